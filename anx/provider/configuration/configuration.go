@@ -1,11 +1,15 @@
 package configuration
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
 	"gopkg.in/yaml.v3"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/cloud-provider/options"
 )
 
@@ -13,22 +17,212 @@ type ProviderConfig struct {
 	Token                  string `yaml:"anexiaToken" split_words:"true"`
 	CustomerID             string `yaml:"customerID,omitempty" split_words:"true"`
 	ClusterName            string `yaml:"clusterName,omitempty" split_words:"true"`
-	AutoDiscoveryTagPrefix string `yaml:"autoDiscoveryTagPrefix,omitempty" split_words:"true" default:"anxkube-ccm-lb"`
+	AutoDiscoveryTagPrefix string `yaml:"autoDiscoveryTagPrefix,omitempty" split_words:"true"`
 
 	// if ccm shall discover $LoadBalancerIdentifier, $SecondaryLoadBalancerIdentifiers and $LoadBalancerPrefixIdentifiers via tag "$AutoDiscoveryTagPrefix-$ClusterName"
 	AutoDiscoverLoadBalancer bool `yaml:"autoDiscoverLoadBalancer,omitempty" split_words:"true"`
 
+	// interval in which $AutoDiscoveryTagPrefix-$ClusterName tagged LoadBalancers are discovered again at runtime, 0 disables rediscovery
+	LoadBalancerDiscoveryInterval time.Duration `yaml:"loadBalancerDiscoveryInterval,omitempty" split_words:"true"`
+
 	// the LBaaS LoadBalancer resource to configure for LoadBalancer Services
 	LoadBalancerIdentifier string `yaml:"loadBalancerIdentifier,omitempty" split_words:"true"`
 
 	// identifiers of LBaaS LoadBalancer resources to keep in sync with $LoadBalancerIdentifier
 	SecondaryLoadBalancerIdentifiers []string `yaml:"secondaryLoadBalancersIdentifiers" split_words:"true"`
 
+	// LBaaS LoadBalancers created for the cluster when auto discovery finds none, not created when nil
+	LoadBalancerProvisioning *LoadBalancerProvisioning `yaml:"loadBalancerProvisioning,omitempty" ignored:"true"`
+
+	// interval in which the states of all LBaaS resources on the LoadBalancers are collected as metrics, 0 disables collection
+	LoadBalancerStateInterval time.Duration `yaml:"loadBalancerStateInterval,omitempty" split_words:"true"`
+
 	// lists the identifiers of prefixes from which external IPs for LoadBalancer Services can be allocated
 	LoadBalancerPrefixIdentifiers []string `yaml:"loadBalancerPrefixIdentifiers,omitempty" split_words:"true"`
 
+	// prefixes ordered for external IPs when no discovered prefix of the requested family exists, none are ordered when nil
+	PrefixProvisioning *PrefixProvisioning `yaml:"prefixProvisioning,omitempty" ignored:"true"`
+
 	// defines the number of retries to wait for LoadBalancer resources to be ready
-	LoadBalancerBackoffSteps int `yaml:"loadBalancerBackoffSteps" default:"30"`
+	LoadBalancerBackoffSteps int `yaml:"loadBalancerBackoffSteps"`
+
+	// CloudDNS zone records for the names given in the "lbaas.anx.io/dns-names" annotation of LoadBalancer Services are
+	// maintained in, no records are maintained when empty
+	CloudDNSZone string `yaml:"cloudDNSZone,omitempty" split_words:"true"`
+
+	// if records for the "lbaas.anx.io/load-balancer-proxy-pass-hostname" annotation of LoadBalancer Services are
+	// maintained in $CloudDNSZone as well, when the hostname is inside it
+	CloudDNSProxyPassHostname bool `yaml:"cloudDNSProxyPassHostname,omitempty" split_words:"true"`
+
+	// TTL of the records created in $CloudDNSZone, in seconds
+	CloudDNSRecordTTL int `yaml:"cloudDNSRecordTTL,omitempty" split_words:"true"`
+
+	// GatewayClasses with this controllerName are handled by the CCM, Gateway API support is disabled when empty
+	GatewayControllerName string `yaml:"gatewayControllerName,omitempty" split_words:"true"`
+
+	// interval in which Gateways of the handled GatewayClasses are synced
+	GatewaySyncInterval time.Duration `yaml:"gatewaySyncInterval,omitempty" split_words:"true"`
+
+	// if reconciliation metrics are labelled with namespace and name of the Service, for up to
+	// $ServiceMetricLabelsLimit Services
+	ServiceMetricLabels      bool `yaml:"serviceMetricLabels,omitempty" split_words:"true"`
+	ServiceMetricLabelsLimit int  `yaml:"serviceMetricLabelsLimit,omitempty" split_words:"true"`
+
+	// OTLP gRPC endpoint (host:port) spans are exported to, tracing is disabled when empty
+	TracingEndpoint string `yaml:"tracingEndpoint,omitempty" split_words:"true"`
+
+	// if the connection to $TracingEndpoint is not secured by TLS
+	TracingInsecure bool `yaml:"tracingInsecure,omitempty" split_words:"true"`
+
+	// ratio of traces sampled, between 0 and 1
+	TracingSamplingRatio float64 `yaml:"tracingSamplingRatio,omitempty" split_words:"true"`
+
+	// networks of the VMs node addresses are taken from, all addresses of the first network are used as InternalIP when empty
+	NodeNetworks []NodeNetwork `yaml:"nodeNetworks,omitempty" ignored:"true"`
+
+	// if the VM name, without the customer prefix, is added as Hostname address to Nodes
+	NodeHostnameAddress bool `yaml:"nodeHostnameAddress,omitempty" split_words:"true"`
+
+	// DNS suffixes appended to the VM name for InternalDNS and ExternalDNS addresses of Nodes, not added when empty
+	NodeInternalDNSSuffix string `yaml:"nodeInternalDNSSuffix,omitempty" split_words:"true"`
+	NodeExternalDNSSuffix string `yaml:"nodeExternalDNSSuffix,omitempty" split_words:"true"`
+
+	// prefix of the additional labels with Anexia specific facts added to Nodes, no additional labels are added when empty
+	NodeLabelPrefix string `yaml:"nodeLabelPrefix" split_words:"true"`
+
+	// prefixes of VM tags synced to Nodes as labels ("$prefix$key=$value") and taints ("$prefix$key=$value:$effect"),
+	// no labels or taints are synced when empty
+	NodeLabelTagPrefix string `yaml:"nodeLabelTagPrefix,omitempty" split_words:"true"`
+	NodeTaintTagPrefix string `yaml:"nodeTaintTagPrefix,omitempty" split_words:"true"`
+
+	// interval in which labels and taints of Nodes are synced from the tags of their VMs
+	NodeTagSyncInterval time.Duration `yaml:"nodeTagSyncInterval,omitempty" split_words:"true"`
+
+	// catalogue of named flavors used as instance type of Nodes, the first flavor matching a VM is used. VMs not
+	// matching any flavor get an instance type generated from their resources, "C$cpus-M$ramGiB-$diskType"
+	Flavors []Flavor `yaml:"flavors,omitempty" ignored:"true"`
+
+	// if provider IDs written to new Nodes include the location code of the VM, "anexia://$location/$identifier"
+	ProviderIDLocation bool `yaml:"providerIDLocation,omitempty" split_words:"true"`
+
+	// strategies to match Nodes to VMs with, applied in the given order until one finds exactly one VM
+	NodeMatchingStrategies []NodeMatchingStrategy `yaml:"nodeMatchingStrategies" split_words:"true"`
+
+	// Node label holding the VM identifier, used by NodeMatchingStrategyLabel
+	NodeMatchingLabel string `yaml:"nodeMatchingLabel" split_words:"true"`
+}
+
+// LoadBalancerProvisioning configures the LBaaS LoadBalancers created for the cluster when auto discovery finds none.
+// They are tagged for auto discovery, taking the usual path afterwards.
+type LoadBalancerProvisioning struct {
+	// LBaaS LoadBalancers to create, e.g. two for a HA pair
+	LoadBalancers []ProvisionedLoadBalancer `yaml:"loadBalancers"`
+
+	// identifier of the prefix to tag for auto discovery of external IPs, none is tagged when empty
+	PrefixIdentifier string `yaml:"prefixIdentifier,omitempty"`
+}
+
+// ProvisionedLoadBalancer is an LBaaS LoadBalancer created for the cluster. Location and size are not configurable,
+// they follow from the LBaaS machine at Address.
+type ProvisionedLoadBalancer struct {
+	// name of the LoadBalancer, "$clusterName-$index" when empty
+	Name string `yaml:"name,omitempty"`
+
+	// IP address of the LBaaS machine, provided by Anexia
+	Address string `yaml:"address"`
+}
+
+// PrefixProvisioning configures the prefixes ordered for external IPs of LoadBalancer Services. Ordered prefixes are
+// tagged for auto discovery, taking the usual path afterwards.
+type PrefixProvisioning struct {
+	// identifier of the location to order prefixes in
+	Location string `yaml:"location"`
+
+	// identifier of the VLAN to order prefixes in, a new VLAN is created with each prefix when empty
+	VLAN string `yaml:"vlan,omitempty"`
+
+	// network masks of the prefixes ordered per family, prefixes of a family are not ordered when 0
+	IPv4NetworkMask int `yaml:"ipv4NetworkMask,omitempty"`
+	IPv6NetworkMask int `yaml:"ipv6NetworkMask,omitempty"`
+}
+
+// NetworkMask returns the network mask of prefixes ordered for the given family, 0 when none are ordered for it or
+// PrefixProvisioning is nil.
+func (p *PrefixProvisioning) NetworkMask(family v1.IPFamily) int {
+	switch {
+	case p == nil:
+		return 0
+	case family == v1.IPv4Protocol:
+		return p.IPv4NetworkMask
+	case family == v1.IPv6Protocol:
+		return p.IPv6NetworkMask
+	}
+
+	return 0
+}
+
+// NodeMatchingStrategy is a way to find the VM of a Node without providerID.
+type NodeMatchingStrategy string
+
+const (
+	// NodeMatchingStrategyName searches VMs by the Node name, with and without customer prefix, comparing IPs when
+	// multiple VMs are found
+	NodeMatchingStrategyName NodeMatchingStrategy = "name"
+
+	// NodeMatchingStrategyTag finds the VM tagged "kubernetes-node-$nodeName"
+	NodeMatchingStrategyTag NodeMatchingStrategy = "tag"
+
+	// NodeMatchingStrategyLabel takes the VM identifier from the Node label configured as NodeMatchingLabel
+	NodeMatchingStrategyLabel NodeMatchingStrategy = "label"
+
+	// NodeMatchingStrategyIP compares the InternalIPs of the Node with the IPs of all VMs with the customer prefix
+	NodeMatchingStrategyIP NodeMatchingStrategy = "ip"
+)
+
+// NodeNetwork selects addresses of a VM to use as node addresses: all addresses of the network attached to VLAN,
+// all addresses inside CIDR or, when both are given, addresses matching both.
+type NodeNetwork struct {
+	VLAN string `yaml:"vlan,omitempty"`
+	CIDR string `yaml:"cidr,omitempty"`
+
+	// type of the selected node addresses, InternalIP (default) or ExternalIP
+	Type v1.NodeAddressType `yaml:"type,omitempty"`
+}
+
+// Flavor names VMs with resources in the given ranges. Minimums and maximums are inclusive, zero values are not
+// checked. RAM is given in GiB, disk size in GB and both disk size and types refer to the largest disk of the VM.
+type Flavor struct {
+	Name string `yaml:"name"`
+
+	MinCPU int `yaml:"minCPU,omitempty"`
+	MaxCPU int `yaml:"maxCPU,omitempty"`
+
+	MinRAM int `yaml:"minRAM,omitempty"`
+	MaxRAM int `yaml:"maxRAM,omitempty"`
+
+	MinDisk int `yaml:"minDisk,omitempty"`
+	MaxDisk int `yaml:"maxDisk,omitempty"`
+
+	// types of the largest disk, any type when empty
+	DiskTypes []string `yaml:"diskTypes,omitempty"`
+}
+
+// defaultProviderConfig returns a ProviderConfig with all default values set. Defaults are applied before
+// parsing the config file and environment, since envconfig defaults would override values from the config file.
+func defaultProviderConfig() ProviderConfig {
+	return ProviderConfig{
+		AutoDiscoveryTagPrefix:        "anxkube-ccm-lb",
+		LoadBalancerDiscoveryInterval: 5 * time.Minute,
+		LoadBalancerBackoffSteps:      30,
+		LoadBalancerStateInterval:     5 * time.Minute,
+		CloudDNSRecordTTL:             300,
+		NodeMatchingStrategies:        []NodeMatchingStrategy{NodeMatchingStrategyName},
+		NodeMatchingLabel:             "anexia.com/vm-identifier",
+		NodeTagSyncInterval:           5 * time.Minute,
+		GatewaySyncInterval:           30 * time.Second,
+		ServiceMetricLabelsLimit:      100,
+		TracingSamplingRatio:          1,
+	}
 }
 
 const (
@@ -52,16 +246,22 @@ var (
 	CloudProviderScheme = fmt.Sprintf("%s://", CloudProviderName)
 )
 
+// NewProviderConfig parses the config file from the given reader, applies the environment and command line flags
+// and validates the result. Unknown keys in the config file are rejected.
 func NewProviderConfig(configReader io.Reader) (ProviderConfig, error) {
-	var providerConfig ProviderConfig
+	providerConfig := defaultProviderConfig()
 	if configReader != nil {
 		config, err := io.ReadAll(configReader)
 		if err != nil {
 			return ProviderConfig{}, err
 		}
-		err = yaml.Unmarshal(config, &providerConfig)
-		if err != nil {
-			return ProviderConfig{}, err
+
+		decoder := yaml.NewDecoder(bytes.NewReader(config))
+		decoder.KnownFields(true)
+
+		// io.EOF is returned for an empty config file
+		if err := decoder.Decode(&providerConfig); err != nil && !errors.Is(err, io.EOF) {
+			return ProviderConfig{}, fmt.Errorf("error parsing cloud-config: %w", err)
 		}
 	}
 
@@ -71,8 +271,15 @@ func NewProviderConfig(configReader io.Reader) (ProviderConfig, error) {
 	}
 
 	err = applyCliFlagsToProviderConfig(&providerConfig)
+	if err != nil {
+		return ProviderConfig{}, err
+	}
+
+	if err := providerConfig.Validate(); err != nil {
+		return ProviderConfig{}, fmt.Errorf("invalid cloud-config: %w", err)
+	}
 
-	return providerConfig, err
+	return providerConfig, nil
 }
 
 func applyCliFlagsToProviderConfig(providerConfig *ProviderConfig) error {
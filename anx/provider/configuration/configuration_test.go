@@ -0,0 +1,248 @@
+package configuration
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestConfiguration(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Configuration")
+}
+
+var _ = Describe("NewProviderConfig", func() {
+	It("applies defaults for values not given", func() {
+		config, err := NewProviderConfig(strings.NewReader("anexiaToken: TOKEN"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(config.AutoDiscoveryTagPrefix).To(Equal("anxkube-ccm-lb"))
+		Expect(config.LoadBalancerBackoffSteps).To(Equal(30))
+		Expect(config.LoadBalancerDiscoveryInterval).To(Equal(5 * time.Minute))
+		Expect(config.NodeLabelPrefix).To(BeEmpty())
+	})
+
+	It("does not override given values with defaults", func() {
+		config, err := NewProviderConfig(strings.NewReader("anexiaToken: TOKEN\nloadBalancerBackoffSteps: 5\nloadBalancerDiscoveryInterval: 1m"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(config.LoadBalancerBackoffSteps).To(Equal(5))
+		Expect(config.LoadBalancerDiscoveryInterval).To(Equal(time.Minute))
+	})
+
+	It("rejects unknown keys", func() {
+		_, err := NewProviderConfig(strings.NewReader("anexiaToken: TOKEN\nloadBalancerIdentifer: typo"))
+		Expect(err).To(MatchError(ContainSubstring("loadBalancerIdentifer")))
+	})
+
+	It("rejects invalid values", func() {
+		_, err := NewProviderConfig(strings.NewReader("anexiaToken: TOKEN\nloadBalancerBackoffSteps: 0"))
+		Expect(err).To(MatchError(ContainSubstring("loadBalancerBackoffSteps")))
+	})
+})
+
+var _ = DescribeTable("Validate",
+	func(modify func(*ProviderConfig), expectedErrors ...string) {
+		config := defaultProviderConfig()
+		config.Token = "TOKEN"
+		config.LoadBalancerIdentifier = "primary"
+		modify(&config)
+
+		err := config.Validate()
+		if len(expectedErrors) == 0 {
+			Expect(err).NotTo(HaveOccurred())
+			return
+		}
+
+		for _, expected := range expectedErrors {
+			Expect(err).To(MatchError(ContainSubstring(expected)))
+		}
+	},
+	Entry("valid static config", func(c *ProviderConfig) {
+		c.SecondaryLoadBalancerIdentifiers = []string{"secondary"}
+		c.LoadBalancerPrefixIdentifiers = []string{"prefix"}
+	}),
+	Entry("valid auto discovery config", func(c *ProviderConfig) {
+		c.LoadBalancerIdentifier = ""
+		c.AutoDiscoverLoadBalancer = true
+		c.ClusterName = "cluster"
+	}),
+	Entry("valid config without LoadBalancers", func(c *ProviderConfig) {
+		c.LoadBalancerIdentifier = ""
+	}),
+	Entry("missing token", func(c *ProviderConfig) {
+		c.Token = ""
+	}, "anexiaToken: Required value"),
+	Entry("zero backoff steps", func(c *ProviderConfig) {
+		c.LoadBalancerBackoffSteps = 0
+	}, "loadBalancerBackoffSteps: Invalid value"),
+	Entry("negative discovery interval", func(c *ProviderConfig) {
+		c.LoadBalancerDiscoveryInterval = -time.Minute
+	}, "loadBalancerDiscoveryInterval: Invalid value"),
+	Entry("negative state interval", func(c *ProviderConfig) {
+		c.LoadBalancerStateInterval = -time.Minute
+	}, "loadBalancerStateInterval: Invalid value"),
+	Entry("auto discovery without cluster name and tag prefix", func(c *ProviderConfig) {
+		c.AutoDiscoverLoadBalancer = true
+		c.AutoDiscoveryTagPrefix = ""
+	}, "clusterName: Required value", "autoDiscoveryTagPrefix: Required value"),
+	Entry("secondaries without primary", func(c *ProviderConfig) {
+		c.LoadBalancerIdentifier = ""
+		c.SecondaryLoadBalancerIdentifiers = []string{"secondary"}
+	}, "loadBalancerIdentifier: Required value"),
+	Entry("invalid secondaries", func(c *ProviderConfig) {
+		c.SecondaryLoadBalancerIdentifiers = []string{"", "secondary", "secondary", "primary"}
+	},
+		"secondaryLoadBalancersIdentifiers[0]: Required value",
+		"secondaryLoadBalancersIdentifiers[2]: Duplicate value",
+		"secondaryLoadBalancersIdentifiers[3]: Invalid value",
+	),
+	Entry("valid CloudDNS zone", func(c *ProviderConfig) {
+		c.CloudDNSZone = "example.com"
+	}),
+	Entry("invalid CloudDNS zone", func(c *ProviderConfig) {
+		c.CloudDNSZone = "example com"
+		c.CloudDNSRecordTTL = 0
+	}, "cloudDNSZone: Invalid value", "cloudDNSRecordTTL: Invalid value"),
+	Entry("proxy pass hostname records without CloudDNS zone", func(c *ProviderConfig) {
+		c.CloudDNSProxyPassHostname = true
+	}, "cloudDNSZone: Required value"),
+	Entry("Service metric labels", func(c *ProviderConfig) {
+		c.ServiceMetricLabels = true
+	}),
+	Entry("Service metric labels without limit", func(c *ProviderConfig) {
+		c.ServiceMetricLabels = true
+		c.ServiceMetricLabelsLimit = 0
+	}, "serviceMetricLabelsLimit: Invalid value"),
+	Entry("tracing", func(c *ProviderConfig) {
+		c.TracingEndpoint = "otel-collector:4317"
+		c.TracingSamplingRatio = 0.1
+	}),
+	Entry("invalid tracing sampling ratio", func(c *ProviderConfig) {
+		c.TracingSamplingRatio = 1.5
+	}, "tracingSamplingRatio: Invalid value"),
+	Entry("valid LoadBalancer provisioning", func(c *ProviderConfig) {
+		c.LoadBalancerIdentifier = ""
+		c.AutoDiscoverLoadBalancer = true
+		c.ClusterName = "cluster"
+		c.LoadBalancerProvisioning = &LoadBalancerProvisioning{
+			LoadBalancers: []ProvisionedLoadBalancer{{Address: "192.0.2.1"}, {Address: "192.0.2.2"}},
+		}
+	}),
+	Entry("invalid LoadBalancer provisioning", func(c *ProviderConfig) {
+		c.LoadBalancerProvisioning = &LoadBalancerProvisioning{
+			LoadBalancers: []ProvisionedLoadBalancer{{Name: "lb", Address: "192.0.2.1"}, {Name: "lb", Address: "lb.example.com"}},
+		}
+	},
+		"autoDiscoverLoadBalancer: Required value",
+		"loadBalancerProvisioning.loadBalancers[1].address: Invalid value",
+		"loadBalancerProvisioning.loadBalancers[1].name: Duplicate value",
+	),
+	Entry("LoadBalancer provisioning without LoadBalancers", func(c *ProviderConfig) {
+		c.LoadBalancerIdentifier = ""
+		c.AutoDiscoverLoadBalancer = true
+		c.ClusterName = "cluster"
+		c.LoadBalancerProvisioning = &LoadBalancerProvisioning{}
+	}, "loadBalancerProvisioning.loadBalancers: Required value"),
+	Entry("valid prefix provisioning", func(c *ProviderConfig) {
+		c.LoadBalancerIdentifier = ""
+		c.AutoDiscoverLoadBalancer = true
+		c.ClusterName = "cluster"
+		c.PrefixProvisioning = &PrefixProvisioning{Location: "location", IPv6NetworkMask: 64}
+	}),
+	Entry("invalid prefix provisioning", func(c *ProviderConfig) {
+		c.PrefixProvisioning = &PrefixProvisioning{IPv4NetworkMask: 31, IPv6NetworkMask: -1}
+	},
+		"autoDiscoverLoadBalancer: Required value",
+		"prefixProvisioning.location: Required value",
+		"prefixProvisioning.ipv4NetworkMask: Invalid value",
+		"prefixProvisioning.ipv6NetworkMask: Invalid value",
+	),
+	Entry("prefix provisioning without network masks", func(c *ProviderConfig) {
+		c.LoadBalancerIdentifier = ""
+		c.AutoDiscoverLoadBalancer = true
+		c.ClusterName = "cluster"
+		c.PrefixProvisioning = &PrefixProvisioning{Location: "location"}
+	}, "prefixProvisioning: Required value"),
+	Entry("valid Gateway controller name", func(c *ProviderConfig) {
+		c.GatewayControllerName = "lbaas.anx.io/gateway-controller"
+	}),
+	Entry("invalid Gateway settings", func(c *ProviderConfig) {
+		c.GatewayControllerName = "gateway-controller"
+		c.GatewaySyncInterval = 0
+	},
+		"gatewayControllerName: Invalid value",
+		"gatewaySyncInterval: Invalid value",
+	),
+	Entry("valid node networks", func(c *ProviderConfig) {
+		c.NodeNetworks = []NodeNetwork{
+			{VLAN: "vlan"},
+			{CIDR: "2001:db8::/32", Type: v1.NodeExternalIP},
+		}
+	}),
+	Entry("invalid node networks", func(c *ProviderConfig) {
+		c.NodeNetworks = []NodeNetwork{
+			{},
+			{CIDR: "10.0.0.1"},
+			{VLAN: "vlan", Type: v1.NodeHostName},
+		}
+	},
+		"nodeNetworks[0]: Required value",
+		"nodeNetworks[1].cidr: Invalid value",
+		"nodeNetworks[2].type: Unsupported value",
+	),
+	Entry("valid DNS suffixes", func(c *ProviderConfig) {
+		c.NodeInternalDNSSuffix = "cluster.internal"
+		c.NodeExternalDNSSuffix = "example.com"
+	}),
+	Entry("invalid DNS suffix", func(c *ProviderConfig) {
+		c.NodeInternalDNSSuffix = ".cluster.internal"
+	}, "nodeInternalDNSSuffix: Invalid value"),
+	Entry("valid node matching strategies", func(c *ProviderConfig) {
+		c.NodeMatchingStrategies = []NodeMatchingStrategy{NodeMatchingStrategyLabel, NodeMatchingStrategyTag, NodeMatchingStrategyName, NodeMatchingStrategyIP}
+	}),
+	Entry("invalid node matching strategies", func(c *ProviderConfig) {
+		c.NodeMatchingStrategies = []NodeMatchingStrategy{"name", "name", "magic", "label"}
+		c.NodeMatchingLabel = "not a label"
+	},
+		"nodeMatchingStrategies[1]: Duplicate value",
+		"nodeMatchingStrategies[2]: Unsupported value",
+		"nodeMatchingLabel: Invalid value",
+	),
+	Entry("valid node tag sync", func(c *ProviderConfig) {
+		c.NodeLabelTagPrefix = "k8s-label:"
+		c.NodeTaintTagPrefix = "k8s-taint:"
+	}),
+	Entry("invalid node tag sync", func(c *ProviderConfig) {
+		c.NodeLabelTagPrefix = "k8s:"
+		c.NodeTaintTagPrefix = "k8s:"
+		c.NodeTagSyncInterval = 0
+	},
+		"nodeTaintTagPrefix: Invalid value",
+		"nodeTagSyncInterval: Invalid value",
+	),
+	Entry("valid flavors", func(c *ProviderConfig) {
+		c.Flavors = []Flavor{
+			{Name: "standard-4-8", MinCPU: 4, MaxCPU: 4, MinRAM: 8, MaxRAM: 8},
+			{Name: "large", MinCPU: 8, DiskTypes: []string{"ENT6"}},
+		}
+	}),
+	Entry("invalid flavors", func(c *ProviderConfig) {
+		c.Flavors = []Flavor{
+			{MinCPU: -1},
+			{Name: "not a label", MinRAM: 16, MaxRAM: 8},
+		}
+	},
+		"flavors[0].name: Required value",
+		"flavors[0].minCPU: Invalid value",
+		"flavors[1].name: Invalid value",
+		"flavors[1].maxRAM: Invalid value",
+	),
+	Entry("duplicate prefixes", func(c *ProviderConfig) {
+		c.LoadBalancerPrefixIdentifiers = []string{"prefix", "prefix"}
+	}, "loadBalancerPrefixIdentifiers[1]: Duplicate value"),
+)
@@ -0,0 +1,311 @@
+package configuration
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Validate checks the ProviderConfig for invalid values, returning an aggregate of all invalid fields found.
+// Fields are referenced by their name in the config file.
+func (c ProviderConfig) Validate() error {
+	errs := field.ErrorList{}
+
+	if c.Token == "" {
+		errs = append(errs, field.Required(field.NewPath("anexiaToken"), "needed to access the Anexia Engine"))
+	}
+
+	if c.LoadBalancerBackoffSteps < 1 {
+		errs = append(errs, field.Invalid(field.NewPath("loadBalancerBackoffSteps"), c.LoadBalancerBackoffSteps, "must be at least 1"))
+	}
+
+	if c.LoadBalancerDiscoveryInterval < 0 {
+		errs = append(errs, field.Invalid(field.NewPath("loadBalancerDiscoveryInterval"), c.LoadBalancerDiscoveryInterval.String(), "must not be negative"))
+	}
+
+	if c.LoadBalancerStateInterval < 0 {
+		errs = append(errs, field.Invalid(field.NewPath("loadBalancerStateInterval"), c.LoadBalancerStateInterval.String(), "must not be negative"))
+	}
+
+	if c.AutoDiscoverLoadBalancer {
+		if c.ClusterName == "" {
+			errs = append(errs, field.Required(field.NewPath("clusterName"), "needed for the auto discovery tag when autoDiscoverLoadBalancer is set"))
+		}
+
+		if c.AutoDiscoveryTagPrefix == "" {
+			errs = append(errs, field.Required(field.NewPath("autoDiscoveryTagPrefix"), "needed when autoDiscoverLoadBalancer is set"))
+		}
+	} else if c.LoadBalancerIdentifier == "" && (len(c.SecondaryLoadBalancerIdentifiers) > 0 || len(c.LoadBalancerPrefixIdentifiers) > 0) {
+		errs = append(errs, field.Required(field.NewPath("loadBalancerIdentifier"), "needed when secondary LoadBalancers or prefixes are configured without autoDiscoverLoadBalancer"))
+	}
+
+	errs = append(errs, validateLoadBalancerProvisioning(c)...)
+	errs = append(errs, validatePrefixProvisioning(c)...)
+	errs = append(errs, validateGateway(c)...)
+	errs = append(errs, validateIdentifiers(field.NewPath("secondaryLoadBalancersIdentifiers"), c.SecondaryLoadBalancerIdentifiers, c.LoadBalancerIdentifier)...)
+	errs = append(errs, validateIdentifiers(field.NewPath("loadBalancerPrefixIdentifiers"), c.LoadBalancerPrefixIdentifiers)...)
+	errs = append(errs, validateDNSSuffix(field.NewPath("cloudDNSZone"), c.CloudDNSZone)...)
+
+	if c.CloudDNSZone != "" && c.CloudDNSRecordTTL < 1 {
+		errs = append(errs, field.Invalid(field.NewPath("cloudDNSRecordTTL"), c.CloudDNSRecordTTL, "must be at least 1"))
+	}
+
+	if c.CloudDNSProxyPassHostname && c.CloudDNSZone == "" {
+		errs = append(errs, field.Required(field.NewPath("cloudDNSZone"), "needed when cloudDNSProxyPassHostname is set"))
+	}
+
+	if c.ServiceMetricLabels && c.ServiceMetricLabelsLimit < 1 {
+		errs = append(errs, field.Invalid(field.NewPath("serviceMetricLabelsLimit"), c.ServiceMetricLabelsLimit, "must be at least 1 when serviceMetricLabels is set"))
+	}
+
+	if c.TracingSamplingRatio < 0 || c.TracingSamplingRatio > 1 {
+		errs = append(errs, field.Invalid(field.NewPath("tracingSamplingRatio"), c.TracingSamplingRatio, "must be between 0 and 1"))
+	}
+
+	errs = append(errs, validateNodeNetworks(field.NewPath("nodeNetworks"), c.NodeNetworks)...)
+	errs = append(errs, validateDNSSuffix(field.NewPath("nodeInternalDNSSuffix"), c.NodeInternalDNSSuffix)...)
+	errs = append(errs, validateDNSSuffix(field.NewPath("nodeExternalDNSSuffix"), c.NodeExternalDNSSuffix)...)
+	errs = append(errs, validateDNSSuffix(field.NewPath("nodeLabelPrefix"), c.NodeLabelPrefix)...)
+	errs = append(errs, validateNodeMatching(c)...)
+	errs = append(errs, validateNodeTagSync(c)...)
+	errs = append(errs, validateFlavors(field.NewPath("flavors"), c.Flavors)...)
+
+	return errs.ToAggregate()
+}
+
+// validateIdentifiers checks the given list of identifiers to not contain empty or duplicate values or any of the
+// given reserved identifiers.
+func validateIdentifiers(path *field.Path, identifiers []string, reserved ...string) field.ErrorList {
+	errs := field.ErrorList{}
+	seen := sets.New[string]()
+
+	for i, identifier := range identifiers {
+		switch {
+		case identifier == "":
+			errs = append(errs, field.Required(path.Index(i), "must not be empty"))
+		case seen.Has(identifier):
+			errs = append(errs, field.Duplicate(path.Index(i), identifier))
+		case sets.New(reserved...).Has(identifier):
+			errs = append(errs, field.Invalid(path.Index(i), identifier, "already configured as primary"))
+		}
+
+		seen.Insert(identifier)
+	}
+
+	return errs
+}
+
+func validateNodeNetworks(path *field.Path, networks []NodeNetwork) field.ErrorList {
+	errs := field.ErrorList{}
+	validTypes := sets.New("", v1.NodeInternalIP, v1.NodeExternalIP)
+
+	for i, network := range networks {
+		if network.VLAN == "" && network.CIDR == "" {
+			errs = append(errs, field.Required(path.Index(i), "vlan or cidr must be given"))
+		}
+
+		if network.CIDR != "" {
+			if _, _, err := net.ParseCIDR(network.CIDR); err != nil {
+				errs = append(errs, field.Invalid(path.Index(i).Child("cidr"), network.CIDR, err.Error()))
+			}
+		}
+
+		if !validTypes.Has(network.Type) {
+			errs = append(errs, field.NotSupported(path.Index(i).Child("type"), network.Type, []v1.NodeAddressType{v1.NodeInternalIP, v1.NodeExternalIP}))
+		}
+	}
+
+	return errs
+}
+
+// validateDNSSuffix checks the given optional DNS suffix, zone or label prefix to be a valid DNS subdomain.
+func validateDNSSuffix(path *field.Path, suffix string) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if suffix == "" {
+		return errs
+	}
+
+	for _, msg := range validation.IsDNS1123Subdomain(suffix) {
+		errs = append(errs, field.Invalid(path, suffix, msg))
+	}
+
+	return errs
+}
+
+func validateNodeMatching(c ProviderConfig) field.ErrorList {
+	errs := field.ErrorList{}
+	path := field.NewPath("nodeMatchingStrategies")
+
+	supported := []NodeMatchingStrategy{NodeMatchingStrategyName, NodeMatchingStrategyTag, NodeMatchingStrategyLabel, NodeMatchingStrategyIP}
+	seen := sets.New[NodeMatchingStrategy]()
+
+	for i, strategy := range c.NodeMatchingStrategies {
+		if !sets.New(supported...).Has(strategy) {
+			errs = append(errs, field.NotSupported(path.Index(i), strategy, supported))
+		} else if seen.Has(strategy) {
+			errs = append(errs, field.Duplicate(path.Index(i), strategy))
+		}
+
+		seen.Insert(strategy)
+	}
+
+	if seen.Has(NodeMatchingStrategyLabel) {
+		for _, msg := range validation.IsQualifiedName(c.NodeMatchingLabel) {
+			errs = append(errs, field.Invalid(field.NewPath("nodeMatchingLabel"), c.NodeMatchingLabel, msg))
+		}
+	}
+
+	return errs
+}
+
+func validateFlavors(path *field.Path, flavors []Flavor) field.ErrorList {
+	errs := field.ErrorList{}
+
+	for i, flavor := range flavors {
+		if flavor.Name == "" {
+			errs = append(errs, field.Required(path.Index(i).Child("name"), "must not be empty"))
+		}
+
+		for _, msg := range validation.IsValidLabelValue(flavor.Name) {
+			errs = append(errs, field.Invalid(path.Index(i).Child("name"), flavor.Name, msg))
+		}
+
+		errs = append(errs, validateRange(path.Index(i), "CPU", flavor.MinCPU, flavor.MaxCPU)...)
+		errs = append(errs, validateRange(path.Index(i), "RAM", flavor.MinRAM, flavor.MaxRAM)...)
+		errs = append(errs, validateRange(path.Index(i), "Disk", flavor.MinDisk, flavor.MaxDisk)...)
+	}
+
+	return errs
+}
+
+// validateRange checks the min$name and max$name fields at path to be non-negative and, when a maximum is given,
+// the minimum not to be larger.
+func validateRange(path *field.Path, name string, minimum, maximum int) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if minimum < 0 {
+		errs = append(errs, field.Invalid(path.Child("min"+name), minimum, "must not be negative"))
+	}
+
+	if maximum < 0 {
+		errs = append(errs, field.Invalid(path.Child("max"+name), maximum, "must not be negative"))
+	} else if maximum > 0 && minimum > maximum {
+		errs = append(errs, field.Invalid(path.Child("max"+name), maximum, "must not be less than min"+name))
+	}
+
+	return errs
+}
+
+func validateNodeTagSync(c ProviderConfig) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if c.NodeLabelTagPrefix == "" && c.NodeTaintTagPrefix == "" {
+		return errs
+	}
+
+	if c.NodeLabelTagPrefix == c.NodeTaintTagPrefix {
+		errs = append(errs, field.Invalid(field.NewPath("nodeTaintTagPrefix"), c.NodeTaintTagPrefix, "must differ from nodeLabelTagPrefix"))
+	}
+
+	if c.NodeTagSyncInterval <= 0 {
+		errs = append(errs, field.Invalid(field.NewPath("nodeTagSyncInterval"), c.NodeTagSyncInterval.String(), "must be positive when syncing tags to Nodes"))
+	}
+
+	return errs
+}
+
+func validateLoadBalancerProvisioning(c ProviderConfig) field.ErrorList {
+	errs := field.ErrorList{}
+	path := field.NewPath("loadBalancerProvisioning")
+
+	if c.LoadBalancerProvisioning == nil {
+		return errs
+	}
+
+	if !c.AutoDiscoverLoadBalancer {
+		errs = append(errs, field.Required(field.NewPath("autoDiscoverLoadBalancer"), "needed when loadBalancerProvisioning is set"))
+	}
+
+	if len(c.LoadBalancerProvisioning.LoadBalancers) == 0 {
+		errs = append(errs, field.Required(path.Child("loadBalancers"), "at least one LoadBalancer to create is needed"))
+	}
+
+	names := sets.New[string]()
+
+	for i, lb := range c.LoadBalancerProvisioning.LoadBalancers {
+		if net.ParseIP(lb.Address) == nil {
+			errs = append(errs, field.Invalid(path.Child("loadBalancers").Index(i).Child("address"), lb.Address, "must be an IP address"))
+		}
+
+		if lb.Name != "" && names.Has(lb.Name) {
+			errs = append(errs, field.Duplicate(path.Child("loadBalancers").Index(i).Child("name"), lb.Name))
+		}
+
+		names.Insert(lb.Name)
+	}
+
+	return errs
+}
+
+func validatePrefixProvisioning(c ProviderConfig) field.ErrorList {
+	errs := field.ErrorList{}
+	path := field.NewPath("prefixProvisioning")
+
+	if c.PrefixProvisioning == nil {
+		return errs
+	}
+
+	if !c.AutoDiscoverLoadBalancer {
+		errs = append(errs, field.Required(field.NewPath("autoDiscoverLoadBalancer"), "needed when prefixProvisioning is set"))
+	}
+
+	if c.PrefixProvisioning.Location == "" {
+		errs = append(errs, field.Required(path.Child("location"), "location to order prefixes in is needed"))
+	}
+
+	if c.PrefixProvisioning.IPv4NetworkMask == 0 && c.PrefixProvisioning.IPv6NetworkMask == 0 {
+		errs = append(errs, field.Required(path, "at least one of ipv4NetworkMask and ipv6NetworkMask is needed"))
+	}
+
+	// the VIP is the address before the broadcast address, which needs at least two host bits
+	errs = append(errs, validateNetworkMask(path.Child("ipv4NetworkMask"), c.PrefixProvisioning.IPv4NetworkMask, 30)...)
+	errs = append(errs, validateNetworkMask(path.Child("ipv6NetworkMask"), c.PrefixProvisioning.IPv6NetworkMask, 126)...)
+
+	return errs
+}
+
+func validateNetworkMask(path *field.Path, mask, maximum int) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if mask < 0 || mask > maximum {
+		errs = append(errs, field.Invalid(path, mask, fmt.Sprintf("must be between 1 and %d, or 0 to disable", maximum)))
+	}
+
+	return errs
+}
+
+func validateGateway(c ProviderConfig) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if c.GatewayControllerName == "" {
+		return errs
+	}
+
+	// controller names are domain prefixed paths, like "example.com/gateway-controller"
+	domain, path, _ := strings.Cut(c.GatewayControllerName, "/")
+	if len(validation.IsDNS1123Subdomain(domain)) > 0 || path == "" {
+		errs = append(errs, field.Invalid(field.NewPath("gatewayControllerName"), c.GatewayControllerName, "must be a domain prefixed path, e.g. lbaas.anx.io/gateway-controller"))
+	}
+
+	if c.GatewaySyncInterval <= 0 {
+		errs = append(errs, field.Invalid(field.NewPath("gatewaySyncInterval"), c.GatewaySyncInterval.String(), "must be positive when Gateway API support is enabled"))
+	}
+
+	return errs
+}
@@ -0,0 +1,56 @@
+package configuration
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Watch polls the cloud-config file at the given path every interval until ctx is done. Each time the file
+// content changed, it is parsed with NewProviderConfig and the result passed to onChange. Parsing errors are
+// passed to onChange as well, letting the caller keep its previous configuration. Errors reading the file are
+// passed to onChange once when they first occur or change, not on every poll.
+//
+// Polling instead of filesystem notifications is intentional: the file is usually mounted from a Secret or
+// ConfigMap, which kubelet updates by swapping symlinks.
+func Watch(ctx context.Context, path string, interval time.Duration, onChange func(ProviderConfig, error)) {
+	_, lastChecksum, _ := readFile(path)
+	var lastReadErr error
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		content, checksum, err := readFile(path)
+		if err != nil {
+			if lastReadErr == nil || lastReadErr.Error() != err.Error() {
+				onChange(ProviderConfig{}, err)
+			}
+
+			lastReadErr = err
+			return
+		}
+
+		lastReadErr = nil
+
+		if bytes.Equal(checksum, lastChecksum) {
+			return
+		}
+
+		lastChecksum = checksum
+
+		onChange(NewProviderConfig(bytes.NewReader(content)))
+	}, interval)
+}
+
+// readFile returns the content of the file at the given path and its checksum.
+func readFile(path string) ([]byte, []byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	checksum := sha256.Sum256(content)
+	return content, checksum[:], nil
+}
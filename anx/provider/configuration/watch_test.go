@@ -0,0 +1,101 @@
+package configuration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Watch", func() {
+	const interval = 10 * time.Millisecond
+
+	var path string
+	var mu sync.Mutex
+	var errs []error
+	var configs []ProviderConfig
+
+	watch := func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		DeferCleanup(cancel)
+
+		go Watch(ctx, path, interval, func(config ProviderConfig, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				errs = append(errs, err)
+			} else {
+				configs = append(configs, config)
+			}
+		})
+
+		// let Watch read the initial file content before the specs change it
+		time.Sleep(5 * interval)
+	}
+
+	reported := func() ([]ProviderConfig, []error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]ProviderConfig{}, configs...), append([]error{}, errs...)
+	}
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "cloud-config")
+		errs = nil
+		configs = nil
+
+		Expect(os.WriteFile(path, []byte("anexiaToken: TOKEN"), 0o600)).To(Succeed())
+	})
+
+	It("passes the changed configuration", func() {
+		watch()
+
+		Expect(os.WriteFile(path, []byte("anexiaToken: TOKEN\nloadBalancerBackoffSteps: 5"), 0o600)).To(Succeed())
+
+		Eventually(func() []ProviderConfig {
+			configs, _ := reported()
+			return configs
+		}).Should(ConsistOf(HaveField("LoadBalancerBackoffSteps", 5)))
+	})
+
+	It("reports an unreadable file once", func() {
+		watch()
+
+		Expect(os.Remove(path)).To(Succeed())
+
+		Eventually(func() []error {
+			_, errs := reported()
+			return errs
+		}).Should(HaveLen(1))
+
+		Consistently(func() []error {
+			_, errs := reported()
+			return errs
+		}, 10*interval, interval).Should(HaveLen(1))
+	})
+
+	It("reports the file becoming unreadable again after it was readable", func() {
+		watch()
+
+		Expect(os.Remove(path)).To(Succeed())
+		Eventually(func() []error {
+			_, errs := reported()
+			return errs
+		}).Should(HaveLen(1))
+
+		Expect(os.WriteFile(path, []byte("anexiaToken: TOKEN"), 0o600)).To(Succeed())
+		time.Sleep(5 * interval)
+
+		Expect(os.Remove(path)).To(Succeed())
+		Eventually(func() []error {
+			_, errs := reported()
+			return errs
+		}).Should(HaveLen(2))
+	})
+})
@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"slices"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/configuration"
+	vminfo "go.anx.io/go-anxcloud/pkg/vsphere/info"
+)
+
+// matchFlavor returns the first of the given flavors matching the resources of the given VM or nil if none does.
+func matchFlavor(info vminfo.Info, flavors []configuration.Flavor) *configuration.Flavor {
+	ram := info.RAM / 1024
+
+	var diskGB float64
+	var diskType string
+	if disk := largestDisk(info); disk != nil {
+		diskGB = disk.DiskGB
+		diskType = disk.DiskType
+	}
+
+	for i, flavor := range flavors {
+		if !inRange(float64(info.CPU), flavor.MinCPU, flavor.MaxCPU) ||
+			!inRange(float64(ram), flavor.MinRAM, flavor.MaxRAM) ||
+			!inRange(diskGB, flavor.MinDisk, flavor.MaxDisk) {
+			continue
+		}
+
+		if len(flavor.DiskTypes) > 0 && !slices.Contains(flavor.DiskTypes, diskType) {
+			continue
+		}
+
+		return &flavors[i]
+	}
+
+	return nil
+}
+
+// inRange checks the value to be inside the inclusive range given by minimum and maximum, zero values are not checked.
+func inRange(value float64, minimum, maximum int) bool {
+	if minimum > 0 && value < float64(minimum) {
+		return false
+	}
+
+	return maximum == 0 || value <= float64(maximum)
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	vminfo "go.anx.io/go-anxcloud/pkg/vsphere/info"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// instanceLabels returns additional labels for the Node of the VM with the given info and tags, all with keys
+// under the given prefix and label-safe values. Facts not known for the VM or not representable as label are omitted.
+func instanceLabels(info vminfo.Info, tags []string, prefix string) map[string]string {
+	facts := map[string]string{
+		"location-name":        info.LocationName,
+		"location-id":          info.LocationID,
+		"cpu-performance-type": info.CPUPerformanceType,
+		"guest-os":             info.GuestOS,
+	}
+
+	if disk := largestDisk(info); disk != nil {
+		facts["disk-type"] = disk.DiskType
+	}
+
+	for _, tag := range tags {
+		facts[sanitizeLabelValue("tag-"+tag)] = "true"
+	}
+
+	ret := make(map[string]string, len(facts))
+
+	for name, value := range facts {
+		key := fmt.Sprintf("%s/%s", prefix, name)
+		value = sanitizeLabelValue(value)
+
+		if value == "" || len(validation.IsQualifiedName(key)) > 0 {
+			continue
+		}
+
+		ret[key] = value
+	}
+
+	return ret
+}
+
+// sanitizeLabelValue replaces every character not allowed in label values with a dash and shortens the result to the
+// allowed length, trimming non-alphanumeric characters from both ends.
+func sanitizeLabelValue(value string) string {
+	var sb strings.Builder
+
+	lastDash := false
+	for _, r := range value {
+		allowed := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '.' || r == '_'
+
+		if allowed {
+			sb.WriteRune(r)
+			lastDash = false
+		} else if !lastDash {
+			sb.WriteRune('-')
+			lastDash = true
+		}
+	}
+
+	ret := sb.String()
+	if len(ret) > validation.LabelValueMaxLength {
+		ret = ret[:validation.LabelValueMaxLength]
+	}
+
+	return strings.TrimFunc(ret, func(r rune) bool {
+		return r == '-' || r == '.' || r == '_'
+	})
+}
+
+// instanceTags retrieves the tags of the VM with the given identifier. Errors are logged and result in no tags, since
+// tags only add optional labels.
+func (i *instanceManager) instanceTags(ctx context.Context, providerID string) []string {
+	tags, err := i.vmTags(ctx, providerID)
+	if err != nil {
+		logr.FromContextOrDiscard(ctx).Error(err, "Error retrieving VM tags, not adding tag labels", "providerID", providerID)
+		return nil
+	}
+
+	return tags
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"slices"
 	"strings"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"go.anx.io/go-anxcloud/pkg/vsphere/powercontrol"
 	v1 "k8s.io/api/core/v1"
 	cloudprovider "k8s.io/cloud-provider"
+	k8smetrics "k8s.io/component-base/metrics"
 	"k8s.io/klog/v2"
 )
 
@@ -22,6 +24,12 @@ type instanceManager struct {
 	Provider
 
 	lastUnauthorizedOrForbiddenInstanceExistCall time.Time
+
+	// cache for VM lookups, disabled when nil
+	vmCache *vmCache
+
+	// counter of unknown power states reported by the Engine, not counted when nil
+	unknownPowerStates *k8smetrics.CounterVec
 }
 
 var (
@@ -34,32 +42,32 @@ func (i *instanceManager) NodeAddressesByProviderID(ctx context.Context, provide
 	if providerID == "" {
 		return nil, errors.New("empty providerId is not allowed")
 	}
-	info, err := i.VSphere().Info().Get(ctx, providerID)
+
+	identifier, _, err := parseProviderID(providerID)
 	if err != nil {
-		return nil, fmt.Errorf("could not get vm infoMock: %w", err)
+		return nil, err
 	}
 
-	if len(info.Network) == 0 {
-		return nil, nil
+	info, err := i.vmInfo(ctx, identifier)
+	if err != nil {
+		return nil, fmt.Errorf("could not get vm infoMock: %w", err)
 	}
-	nodeAddresses := make([]v1.NodeAddress, 0, len(info.Network))
-	if len(info.Network) > 1 {
-		klog.Warningf("found multiple networks for VM '%s'. This can potentially break stuff. Since only the first one"+
-			"will be used", providerID)
+
+	return i.nodeAddressesFromInfo(providerID, info), nil
+}
+
+func (i *instanceManager) nodeAddressesFromInfo(providerID string, info vminfo.Info) []v1.NodeAddress {
+	networks := i.Config().NodeNetworks
+	if len(info.Network) > 1 && len(networks) == 0 {
+		klog.Warningf("found multiple networks for VM '%s'. Only the first one will be used, configure nodeNetworks "+
+			"to select others", providerID)
 		for index, Networks := range info.Network {
 			klog.Warningf("List IPs Index:%d : %s", index, Networks.IPv4)
 		}
-
-	}
-
-	for _, ip := range info.Network[0].IPv4 {
-		nodeAddresses = append(nodeAddresses, v1.NodeAddress{
-			Type:    "InternalIP",
-			Address: ip,
-		})
 	}
 
-	return nodeAddresses, nil
+	addresses := nodeAddresses(info, networks)
+	return append(addresses, nodeHostnameAddresses(info, i.Config())...)
 }
 
 func (i *instanceManager) handleUnauthorizedForbidden(err error) {
@@ -79,7 +87,7 @@ func (i *instanceManager) InstanceExists(ctx context.Context, node *v1.Node) (bo
 		return false, err
 	}
 
-	_, err = i.VSphere().Info().Get(ctx, providerID)
+	_, err = i.vmInfo(ctx, providerID)
 	if err == nil {
 		return true, nil
 	}
@@ -99,19 +107,27 @@ func (i *instanceManager) InstanceShutdown(ctx context.Context, node *v1.Node) (
 		return false, err
 	}
 
-	state, err := i.VSphere().PowerControl().Get(ctx, providerID)
+	info, err := i.vmInfo(ctx, providerID)
 	if err != nil {
-		return false, fmt.Errorf("could not get power state of '%s': %w", providerID, err)
+		return false, fmt.Errorf("could not get vm info of '%s': %w", providerID, err)
 	}
 
-	switch state {
-	case powercontrol.OnState:
-		return false, nil
-	case powercontrol.OffState:
-		return true, nil
-	default:
-		return false, fmt.Errorf("unkown power state '%s'", state)
+	var state powercontrol.State
+	if !strings.EqualFold(info.Status, vmStatusDeprovisioning) {
+		state, err = i.vmPowerState(ctx, providerID)
+		if err != nil {
+			return false, fmt.Errorf("could not get power state of '%s': %w", providerID, err)
+		}
+	}
+
+	shutdown, err := isShutdown(info.Status, state)
+
+	var unknownPowerState UnknownPowerStateError
+	if errors.As(err, &unknownPowerState) && i.unknownPowerStates != nil {
+		i.unknownPowerStates.WithLabelValues(string(unknownPowerState.State)).Inc()
 	}
+
+	return shutdown, err
 }
 
 func (i *instanceManager) InstanceMetadata(ctx context.Context, node *v1.Node) (*cloudprovider.InstanceMetadata, error) {
@@ -120,22 +136,25 @@ func (i *instanceManager) InstanceMetadata(ctx context.Context, node *v1.Node) (
 		return nil, err
 	}
 
-	nodeAddresses, err := i.NodeAddressesByProviderID(ctx, providerID)
+	info, err := i.vmInfo(ctx, providerID)
 	if err != nil {
 		return nil, err
 	}
 
-	info, err := i.VSphere().Info().Get(ctx, providerID)
-	if err != nil {
-		return nil, err
+	var additionalLabels map[string]string
+	if prefix := i.Config().NodeLabelPrefix; prefix != "" {
+		additionalLabels = instanceLabels(info, i.instanceTags(ctx, providerID), prefix)
 	}
 
+	zone := instanceZone(info)
+
 	return &cloudprovider.InstanceMetadata{
-		ProviderID:    providerID,
-		InstanceType:  instanceType(info),
-		NodeAddresses: nodeAddresses,
-		Zone:          info.LocationCode,
-		Region:        info.LocationCountry,
+		ProviderID:       i.providerID(providerID, info.LocationCode),
+		InstanceType:     instanceType(info, i.Config().Flavors),
+		NodeAddresses:    i.nodeAddressesFromInfo(providerID, info),
+		Zone:             zone.FailureDomain,
+		Region:           zone.Region,
+		AdditionalLabels: additionalLabels,
 	}, nil
 }
 
@@ -179,7 +198,7 @@ func (i *instanceManager) filterInstances(ctx context.Context, node *v1.Node, vm
 
 	filtered := make([]string, 0, len(vms))
 	for _, vm := range vms {
-		fullVM, err := i.VSphere().Info().Get(ctx, vm)
+		fullVM, err := i.vmInfo(ctx, vm)
 		if err != nil {
 			logger.Error(err, "Error retrieving full VM details", "identifier", vm)
 		}
@@ -187,7 +206,8 @@ func (i *instanceManager) filterInstances(ctx context.Context, node *v1.Node, vm
 		for _, network := range fullVM.Network {
 			for _, ip := range append(network.IPv4, network.IPv6...) {
 				for _, nodeIP := range nodeIPs {
-					if nodeIP.Equal(net.ParseIP(ip)) {
+					// a VM matching multiple IPs of the Node is still only one VM
+					if nodeIP.Equal(net.ParseIP(ip)) && !slices.Contains(filtered, fullVM.Identifier) {
 						filtered = append(filtered, fullVM.Identifier)
 					}
 				}
@@ -200,37 +220,30 @@ func (i *instanceManager) filterInstances(ctx context.Context, node *v1.Node, vm
 
 func (i *instanceManager) InstanceIDByNode(ctx context.Context, node *v1.Node) (string, error) {
 	if node.Spec.ProviderID != "" {
-		return strings.TrimPrefix(node.Spec.ProviderID, configuration.CloudProviderScheme), nil
-	}
-
-	logger := logr.FromContextOrDiscard(ctx).WithValues("nodeName", node.Name)
-
-	vms, err := i.instancesByName(ctx, node.Name)
-	if err != nil {
-		return "", err
+		identifier, _, err := parseProviderID(node.Spec.ProviderID)
+		return identifier, err
 	}
 
-	if len(vms) > 1 {
-		logger.Info("Found multiple VMs matching node.Name, filtering by IPs now")
-
-		vms = i.filterInstances(ctx, node, vms)
+	return i.matchNode(ctx, node)
+}
 
-		if len(vms) > 1 {
-			logger.Info("Found multiple VMs matching node.Name and having the expected IP address - giving up")
-			return "", errVirtualMachineNameNotUnique
-		}
+// instanceType returns the name of the first of the given flavors matching the VM or, if none does, a name generated
+// from its resources. The result is always a valid label value.
+func instanceType(info vminfo.Info, flavors []configuration.Flavor) string {
+	if flavor := matchFlavor(info, flavors); flavor != nil {
+		return flavor.Name
 	}
 
-	if len(vms) == 1 {
-		return vms[0], nil
+	cores := info.CPU
+	ram := info.RAM / 1024
+	if disk := largestDisk(info); disk != nil {
+		return sanitizeLabelValue(fmt.Sprintf("C%d-M%d-%s", cores, ram, disk.DiskType))
 	}
-
-	return "", errNamedVirtualMachineNotFound
+	return fmt.Sprintf("C%d-M%d", cores, ram)
 }
 
-func instanceType(info vminfo.Info) string {
-	cores := info.CPU
-	ram := info.RAM / 1024
+// largestDisk returns the largest disk of the VM with a known DiskType or nil if there is none.
+func largestDisk(info vminfo.Info) *vminfo.DiskInfo {
 	var largestDisk *vminfo.DiskInfo
 	for _, diskInfo := range info.DiskInfo {
 		// skip if DiskType is zero-val as this will result in a trailing `-`
@@ -239,10 +252,7 @@ func instanceType(info vminfo.Info) string {
 			largestDisk = &diskInfo
 		}
 	}
-	if largestDisk != nil {
-		return fmt.Sprintf("C%d-M%d-%s", cores, ram, largestDisk.DiskType)
-	}
-	return fmt.Sprintf("C%d-M%d", cores, ram)
+	return largestDisk
 }
 
 func nodeInternalIPs(node *v1.Node) []net.IP {
@@ -8,6 +8,7 @@ import (
 	"math/rand"
 	"net/http"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -308,6 +309,7 @@ func TestInstanceShutdown(t *testing.T) {
 				Identifier: identifier,
 			}}, nil)
 
+		provider.InfoMock.On("Get", ctx, identifier).Return(info.Info{Status: "poweredOn"}, nil)
 		provider.PowerControlMock.On("Get", ctx, identifier).Return(powercontrol.OnState, nil)
 
 		manager := instanceManager{Provider: provider}
@@ -324,6 +326,7 @@ func TestInstanceShutdown(t *testing.T) {
 				Identifier: identifier,
 			}}, nil)
 
+		provider.InfoMock.On("Get", ctx, identifier).Return(info.Info{Status: "poweredOn"}, nil)
 		provider.PowerControlMock.On("Get", ctx, identifier).Return(powercontrol.OffState, nil)
 
 		manager := instanceManager{Provider: provider}
@@ -340,12 +343,85 @@ func TestInstanceShutdown(t *testing.T) {
 				Identifier: identifier,
 			}}, nil)
 
+		provider.InfoMock.On("Get", ctx, identifier).Return(info.Info{Status: "poweredOn"}, nil)
 		provider.PowerControlMock.On("Get", ctx, identifier).Return(powercontrol.State("NoExistentState"), nil)
 
 		manager := instanceManager{Provider: provider}
 		_, err := manager.InstanceShutdown(ctx, &node)
-		require.Error(t, err)
+		require.ErrorAs(t, err, &UnknownPowerStateError{})
 	})
+
+	t.Run("Suspended", func(t *testing.T) {
+		t.Parallel()
+		provider := tUtils.GetMockedAnxProvider()
+		provider.InfoMock.On("Get", ctx, identifier).Return(info.Info{Status: "suspended"}, nil)
+		provider.PowerControlMock.On("Get", ctx, identifier).Return(powercontrol.State("suspended"), nil)
+
+		manager := instanceManager{Provider: provider}
+		isShutdown, err := manager.InstanceShutdown(ctx, &node)
+		require.NoError(t, err)
+		require.True(t, isShutdown)
+	})
+
+	t.Run("Transitional", func(t *testing.T) {
+		t.Parallel()
+
+		for state, expected := range map[powercontrol.State]bool{
+			"poweringOn":   false,
+			"rebooting":    false,
+			"resetting":    false,
+			"poweringOff":  true,
+			"shuttingDown": true,
+		} {
+			provider := tUtils.GetMockedAnxProvider()
+			provider.InfoMock.On("Get", ctx, identifier).Return(info.Info{Status: "poweredOn"}, nil)
+			provider.PowerControlMock.On("Get", ctx, identifier).Return(state, nil)
+
+			manager := instanceManager{Provider: provider}
+			isShutdown, err := manager.InstanceShutdown(ctx, &node)
+			require.NoError(t, err, state)
+			require.Equal(t, expected, isShutdown, state)
+		}
+	})
+
+	t.Run("Deprovisioning", func(t *testing.T) {
+		t.Parallel()
+		provider := tUtils.GetMockedAnxProvider()
+		provider.InfoMock.On("Get", ctx, identifier).Return(info.Info{Status: "deprovisioning"}, nil)
+
+		manager := instanceManager{Provider: provider}
+		isShutdown, err := manager.InstanceShutdown(ctx, &node)
+		require.NoError(t, err)
+		require.True(t, isShutdown)
+		provider.PowerControlMock.AssertNotCalled(t, "Get", ctx, identifier)
+	})
+}
+
+func TestIsShutdown(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		status   string
+		state    powercontrol.State
+		expected bool
+	}{
+		{"poweredOn", powercontrol.OnState, false},
+		{"poweredOn", "poweringOn", false},
+		{"poweredOn", "rebooting", false},
+		{"poweredOff", powercontrol.OffState, true},
+		{"poweredOn", "poweringOff", true},
+		{"suspended", "suspended", true},
+		{"Deprovisioning", powercontrol.OnState, true},
+	}
+
+	for _, testCase := range testCases {
+		shutdown, err := isShutdown(testCase.status, testCase.state)
+		require.NoError(t, err)
+		require.Equal(t, testCase.expected, shutdown, "%s/%s", testCase.status, testCase.state)
+	}
+
+	_, err := isShutdown("poweredOn", "somethingNew")
+	require.Equal(t, UnknownPowerStateError{State: "somethingNew"}, err)
 }
 
 func TestInstanceTypeFromInfo(t *testing.T) {
@@ -365,7 +441,7 @@ func TestInstanceTypeFromInfo(t *testing.T) {
 					DiskGB:   100,
 				},
 			},
-		})
+		}, nil)
 
 		require.Equal(t, "C5-M4-ENT7", instanceTypeStr)
 	})
@@ -384,7 +460,7 @@ func TestInstanceTypeFromInfo(t *testing.T) {
 					DiskGB: 100,
 				},
 			},
-		})
+		}, nil)
 
 		require.Equal(t, "C5-M4-ENT6", instanceTypeStr)
 	})
@@ -402,7 +478,7 @@ func TestInstanceTypeFromInfo(t *testing.T) {
 					DiskGB: 100,
 				},
 			},
-		})
+		}, nil)
 
 		require.Equal(t, "C5-M4", instanceTypeStr)
 	})
@@ -412,11 +488,44 @@ func TestInstanceTypeFromInfo(t *testing.T) {
 		instanceTypeStr := instanceType(info.Info{
 			RAM: 4096,
 			CPU: 5,
-		})
+		}, nil)
 
 		require.Equal(t, "C5-M4", instanceTypeStr)
 	})
 
+	t.Run("InvalidDiskType", func(t *testing.T) {
+		t.Parallel()
+		instanceTypeStr := instanceType(info.Info{
+			RAM:      4096,
+			CPU:      5,
+			DiskInfo: []info.DiskInfo{{DiskType: "ENT 6/fast", DiskGB: 5}},
+		}, nil)
+
+		require.Equal(t, "C5-M4-ENT-6-fast", instanceTypeStr)
+	})
+
+	t.Run("Flavors", func(t *testing.T) {
+		t.Parallel()
+		flavors := []configuration.Flavor{
+			{Name: "small", MaxCPU: 2, MaxRAM: 4},
+			{Name: "standard-4-8", MinCPU: 4, MaxCPU: 4, MinRAM: 8, MaxRAM: 8, DiskTypes: []string{"ENT6"}},
+			{Name: "large-disk", MinDisk: 500},
+		}
+
+		vm := func(cpu, ramGiB int, diskGB float64) info.Info {
+			return info.Info{
+				CPU:      cpu,
+				RAM:      ramGiB * 1024,
+				DiskInfo: []info.DiskInfo{{DiskType: "ENT6", DiskGB: diskGB}},
+			}
+		}
+
+		require.Equal(t, "small", instanceType(vm(2, 4, 20), flavors))
+		require.Equal(t, "standard-4-8", instanceType(vm(4, 8, 20), flavors))
+		require.Equal(t, "large-disk", instanceType(vm(4, 16, 500), flavors))
+		require.Equal(t, "C4-M16-ENT6", instanceType(vm(4, 16, 20), flavors))
+	})
+
 }
 
 func TestInstanceMetadata(t *testing.T) {
@@ -443,6 +552,7 @@ func TestInstanceMetadata(t *testing.T) {
 
 		metadata, err := manager.InstanceMetadata(ctx, &node)
 		require.NoError(t, err)
+		require.Equal(t, identifier, metadata.ProviderID)
 		require.Equal(t, metadata.InstanceType, "C5-M4")
 		require.Equal(t, metadata.Zone, "AT04")
 		require.Equal(t, metadata.Region, "AT")
@@ -480,6 +590,219 @@ func TestInstanceMetadata(t *testing.T) {
 		require.Equal(t, string(metadata.NodeAddresses[0].Type), "InternalIP")
 	})
 
+	t.Run("ProviderIDLocation", func(t *testing.T) {
+		t.Parallel()
+		provider := tUtils.GetMockedAnxProvider()
+		provider.ProviderConfig.ProviderIDLocation = true
+		provider.InfoMock.On("Get", ctx, identifier).Return(info.Info{
+			LocationCountry: "AT",
+			LocationCode:    "AT04",
+		}, nil)
+		manager := instanceManager{Provider: provider}
+
+		metadata, err := manager.InstanceMetadata(ctx, &node)
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("%sAT04/%s", configuration.CloudProviderScheme, identifier), metadata.ProviderID)
+	})
+
+}
+
+func TestProviderID(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		providerID string
+		identifier string
+		location   string
+		err        bool
+	}{
+		{providerID: "anexia://1234", identifier: "1234"},
+		{providerID: "anexia://AT04/1234", identifier: "1234", location: "AT04"},
+		{providerID: "1234", identifier: "1234"},
+		{providerID: "anexia://", err: true},
+		{providerID: "anexia://AT04/", err: true},
+		{providerID: "anexia:///1234", err: true},
+		{providerID: "anexia://AT04/1234/5678", err: true},
+	} {
+		t.Run(tc.providerID, func(t *testing.T) {
+			identifier, location, err := parseProviderID(tc.providerID)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.identifier, identifier)
+			require.Equal(t, tc.location, location)
+
+			if strings.HasPrefix(tc.providerID, configuration.CloudProviderScheme) {
+				require.Equal(t, tc.providerID, formatProviderID(identifier, location))
+			}
+		})
+	}
+}
+
+func TestNodeAddresses(t *testing.T) {
+	t.Parallel()
+
+	vm := info.Info{
+		Network: []info.Network{
+			{
+				VLAN: "internal-vlan",
+				IPv4: []string{"10.0.0.1"},
+				IPv6: []string{"fe80::1", "fd00::1"},
+			},
+			{
+				VLAN: "public-vlan",
+				IPv4: []string{"192.0.2.1"},
+				IPv6: []string{"2001:db8::1"},
+			},
+		},
+	}
+
+	internal := func(address string) v1.NodeAddress {
+		return v1.NodeAddress{Type: v1.NodeInternalIP, Address: address}
+	}
+
+	external := func(address string) v1.NodeAddress {
+		return v1.NodeAddress{Type: v1.NodeExternalIP, Address: address}
+	}
+
+	testCases := []struct {
+		name     string
+		networks []configuration.NodeNetwork
+		expected []v1.NodeAddress
+	}{
+		{
+			// IPv4 addresses of the first network only, as before networks could be configured
+			name:     "NotConfigured",
+			expected: []v1.NodeAddress{internal("10.0.0.1")},
+		},
+		{
+			name: "IPv6SelectedExplicitly",
+			networks: []configuration.NodeNetwork{
+				{VLAN: "internal-vlan"},
+			},
+			expected: []v1.NodeAddress{internal("10.0.0.1"), internal("fd00::1")},
+		},
+		{
+			name: "ByVLAN",
+			networks: []configuration.NodeNetwork{
+				{VLAN: "public-vlan", Type: v1.NodeExternalIP},
+				{VLAN: "internal-vlan"},
+			},
+			expected: []v1.NodeAddress{
+				internal("10.0.0.1"), internal("fd00::1"),
+				external("192.0.2.1"), external("2001:db8::1"),
+			},
+		},
+		{
+			name: "ByCIDR",
+			networks: []configuration.NodeNetwork{
+				{CIDR: "2001:db8::/32"},
+				{CIDR: "10.0.0.0/8"},
+			},
+			expected: []v1.NodeAddress{internal("10.0.0.1"), internal("2001:db8::1")},
+		},
+		{
+			name: "ByVLANAndCIDR",
+			networks: []configuration.NodeNetwork{
+				{VLAN: "internal-vlan", CIDR: "192.0.2.0/24"},
+			},
+			expected: []v1.NodeAddress{},
+		},
+		{
+			name: "SelectedTwice",
+			networks: []configuration.NodeNetwork{
+				{CIDR: "192.0.2.0/24", Type: v1.NodeExternalIP},
+				{VLAN: "public-vlan"},
+			},
+			expected: []v1.NodeAddress{internal("2001:db8::1"), external("192.0.2.1")},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, testCase.expected, nodeAddresses(vm, testCase.networks))
+		})
+	}
+}
+
+func TestNodeHostnameAddresses(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		t.Parallel()
+		addresses := nodeHostnameAddresses(info.Info{Name: "test-node01"}, &configuration.ProviderConfig{})
+		require.Empty(t, addresses)
+	})
+
+	t.Run("AllConfigured", func(t *testing.T) {
+		t.Parallel()
+		addresses := nodeHostnameAddresses(info.Info{Name: "TEST-Node01"}, &configuration.ProviderConfig{
+			CustomerID:            "test",
+			NodeHostnameAddress:   true,
+			NodeInternalDNSSuffix: "cluster.internal",
+			NodeExternalDNSSuffix: "example.com",
+		})
+		require.Equal(t, []v1.NodeAddress{
+			{Type: v1.NodeHostName, Address: "node01"},
+			{Type: v1.NodeInternalDNS, Address: "node01.cluster.internal"},
+			{Type: v1.NodeExternalDNS, Address: "node01.example.com"},
+		}, addresses)
+	})
+
+	t.Run("InvalidName", func(t *testing.T) {
+		t.Parallel()
+		addresses := nodeHostnameAddresses(info.Info{Name: "test-node_01"}, &configuration.ProviderConfig{
+			CustomerID:          "test",
+			NodeHostnameAddress: true,
+		})
+		require.Empty(t, addresses)
+	})
+}
+
+func TestInstanceLabels(t *testing.T) {
+	t.Parallel()
+
+	labels := instanceLabels(info.Info{
+		LocationID:         "52b5f6b2fd3a4a7eaaedf1a7c019e9ea",
+		LocationName:       "AT, Vienna, Datasix",
+		CPUPerformanceType: "performance",
+		GuestOS:            "Flatcar Container Linux (64-bit)",
+		DiskInfo: []info.DiskInfo{
+			{DiskType: "STD1", DiskGB: 10},
+			{DiskType: "ENT6", DiskGB: 100},
+		},
+	}, []string{"kubernetes", "team: platform"}, "anexia.com")
+
+	require.Equal(t, map[string]string{
+		"anexia.com/location-name":        "AT-Vienna-Datasix",
+		"anexia.com/location-id":          "52b5f6b2fd3a4a7eaaedf1a7c019e9ea",
+		"anexia.com/cpu-performance-type": "performance",
+		"anexia.com/guest-os":             "Flatcar-Container-Linux-64-bit",
+		"anexia.com/disk-type":            "ENT6",
+		"anexia.com/tag-kubernetes":       "true",
+		"anexia.com/tag-team-platform":    "true",
+	}, labels)
+}
+
+func TestSanitizeLabelValue(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]string{
+		"already-valid":         "already-valid",
+		"  spaces around  ":     "spaces-around",
+		"(braces) & symbols!":   "braces-symbols",
+		"ümlauts":               "mlauts",
+		"---":                   "",
+		strings.Repeat("a", 70): strings.Repeat("a", 63),
+	}
+
+	for value, expected := range testCases {
+		require.Equal(t, expected, sanitizeLabelValue(value), value)
+	}
 }
 
 func randomNodeIdentifier() string {
@@ -5,19 +5,25 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
 
 	"go.anx.io/go-anxcloud/pkg/api"
 	"go.anx.io/go-anxcloud/pkg/api/types"
 	corev1 "go.anx.io/go-anxcloud/pkg/apis/core/v1"
 	"go.anx.io/go-anxcloud/pkg/client"
 	"go.anx.io/go-anxcloud/pkg/ipam"
+	anxprefix "go.anx.io/go-anxcloud/pkg/ipam/prefix"
 
 	v1 "k8s.io/api/core/v1"
 	cloudprovider "k8s.io/cloud-provider"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/configuration"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/tracing"
 )
 
 const (
@@ -27,8 +33,14 @@ const (
 )
 
 var (
-	errInvalidIPFamiliesAnnotation = fmt.Errorf("invalid IP family in annotation %v", lbaasExternalIPFamiliesAnnotation)
-	errFamilyMismatch              = errors.New("requested family does not match prefix family")
+	errFamilyMismatch = errors.New("requested family does not match prefix family")
+
+	// ErrInvalidIPFamiliesAnnotation is returned for Services with invalid IP families in their annotation
+	ErrInvalidIPFamiliesAnnotation = fmt.Errorf("invalid IP family in annotation %v", lbaasExternalIPFamiliesAnnotation)
+
+	// ErrPrefixPending is returned when no address could be allocated because a discovered prefix is not usable yet,
+	// e.g. because it was ordered recently
+	ErrPrefixPending = errors.New("waiting for discovered prefix to become usable")
 )
 
 // Manager allocates external IP addresses for services
@@ -53,10 +65,12 @@ func NewWithPrefixes(ctx context.Context, apiClient api.API, legacyClient client
 	return m, nil
 }
 
-// NewWithAutoDiscovery creates a new Manager instance doing prefix and address auto discovery
-func NewWithAutoDiscovery(ctx context.Context, apiClient api.API, legacyClient client.Client, autoDiscoveryName string) Manager {
+// NewWithAutoDiscovery creates a new Manager instance doing prefix and address auto discovery. When provisioning is not
+// nil, new prefixes are ordered for families no prefix is discovered for.
+func NewWithAutoDiscovery(ctx context.Context, apiClient api.API, legacyClient client.Client, autoDiscoveryName string, provisioning *configuration.PrefixProvisioning) Manager {
 	m := newMgr(ctx, apiClient, legacyClient)
 	m.autoDiscoveryName = &autoDiscoveryName
+	m.provisioning = provisioning
 	return m
 }
 
@@ -71,6 +85,19 @@ type mgr struct {
 	// statically configured prefixes
 	fixedPrefixes []*prefix
 
+	// prefixes are ordered when no prefix of the requested family is discovered and provisioning is not nil
+	provisioning *configuration.PrefixProvisioning
+
+	// identifiers of prefixes ordered and tagged by this manager and not usable yet, by family
+	orderedPrefixes map[v1.IPFamily][]string
+
+	// identifiers of prefixes ordered by this manager but not tagged for auto discovery yet, by family
+	untaggedPrefixes map[v1.IPFamily][]string
+
+	// identifiers of discovered prefixes of known family not usable yet, e.g. because they are still being created,
+	// by family
+	pendingPrefixes map[v1.IPFamily][]string
+
 	prefixCache          []*prefix
 	prefixCacheTimestamp time.Time
 }
@@ -119,7 +146,9 @@ func (m *mgr) AllocateAddresses(ctx context.Context, svc *v1.Service) ([]string,
 		if !ok {
 			m.logger.V(1).Info("No addresses for IP family allocated yet", "family", fam)
 
-			addr, err := m.allocateAddress(ctx, fam)
+			allocateCtx, span := tracing.Start(ctx, "allocateAddress", attribute.String("ip.family", string(fam)))
+			addr, err := m.allocateAddress(allocateCtx, fam)
+			tracing.End(span, err)
 			if err != nil {
 				return nil, fmt.Errorf("error allocating address for family %q: %w", fam, err)
 			}
@@ -145,32 +174,29 @@ func (m *mgr) prefixes(ctx context.Context) ([]*prefix, error) {
 	}
 
 	ret := make([]*prefix, 0)
+	pending := make(map[v1.IPFamily][]string)
 
 	if len(m.fixedPrefixes) > 0 {
 		ret = append(ret, m.fixedPrefixes...)
 	}
 
 	if m.autoDiscoveryName != nil {
-		ctx, cancel := context.WithCancel(ctx)
-		defer cancel()
-
-		var oc types.ObjectChannel
-		tag := fmt.Sprintf("kubernetes-lb-prefix-%s", *m.autoDiscoveryName)
-		err := m.api.List(ctx, &corev1.Resource{Tags: []string{tag}}, api.ObjectChannel(&oc), api.FullObjects(true))
+		identifiers, err := DiscoverPrefixes(ctx, m.api, *m.autoDiscoveryName)
 		if err != nil {
-			return nil, fmt.Errorf("error listing resources with tag: %w", err)
+			return nil, err
 		}
 
-		for retriever := range oc {
-			var res corev1.Resource
-			err := retriever(&res)
+		for _, identifier := range identifiers {
+			p, err := newPrefix(ctx, m.api, m.ipam, identifier, m.autoDiscoveryName)
 			if err != nil {
-				return nil, fmt.Errorf("error retrieving resource with tag: %w", err)
-			}
+				m.logger.Error(err, "Retrieving prefix failed, doing my best continuing", "identifier", identifier)
+
+				// only prefixes of known family keep us from ordering another one of the same family
+				var unusable *unusablePrefixError
+				if errors.As(err, &unusable) {
+					pending[unusable.family] = append(pending[unusable.family], identifier)
+				}
 
-			p, err := newPrefix(ctx, m.api, m.ipam, res.Identifier, m.autoDiscoveryName)
-			if err != nil {
-				m.logger.Error(err, "Retrieving prefix failed, doing my best continuing", "identifier", res.Identifier)
 				continue
 			}
 
@@ -181,6 +207,61 @@ func (m *mgr) prefixes(ctx context.Context) ([]*prefix, error) {
 
 	m.prefixCache = ret
 	m.prefixCacheTimestamp = time.Now()
+	m.pendingPrefixes = pending
+
+	// ordered prefixes are no longer pending once usable
+	for fam, identifiers := range m.orderedPrefixes {
+		m.orderedPrefixes[fam] = slices.DeleteFunc(identifiers, func(identifier string) bool {
+			return slices.ContainsFunc(ret, func(p *prefix) bool { return p.identifier == identifier })
+		})
+	}
+
+	return ret, nil
+}
+
+// pendingPrefixIdentifiers returns the identifiers of the prefixes of the given family not usable yet, either ordered
+// by this manager or discovered with known family.
+func (m *mgr) pendingPrefixIdentifiers(fam v1.IPFamily) []string {
+	ret := slices.Clone(m.orderedPrefixes[fam])
+
+	for _, identifier := range m.pendingPrefixes[fam] {
+		if !slices.Contains(ret, identifier) {
+			ret = append(ret, identifier)
+		}
+	}
+
+	return ret
+}
+
+// PrefixDiscoveryTag returns the tag LoadBalancer prefixes are discovered with for the given auto discovery name.
+func PrefixDiscoveryTag(autoDiscoveryName string) string {
+	return fmt.Sprintf("kubernetes-lb-prefix-%s", autoDiscoveryName)
+}
+
+// DiscoverPrefixes returns the identifiers of all resources tagged as LoadBalancer prefix for the given
+// auto discovery name.
+func DiscoverPrefixes(ctx context.Context, apiClient api.API, autoDiscoveryName string) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var oc types.ObjectChannel
+	tag := PrefixDiscoveryTag(autoDiscoveryName)
+	err := apiClient.List(ctx, &corev1.Resource{Tags: []string{tag}}, api.ObjectChannel(&oc), api.FullObjects(true))
+	if err != nil {
+		return nil, fmt.Errorf("error listing resources with tag: %w", err)
+	}
+
+	ret := make([]string, 0)
+
+	for retriever := range oc {
+		var res corev1.Resource
+		err := retriever(&res)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving resource with tag: %w", err)
+		}
+
+		ret = append(ret, res.Identifier)
+	}
 
 	return ret, nil
 }
@@ -206,9 +287,109 @@ func (m *mgr) allocateAddress(ctx context.Context, fam v1.IPFamily) (net.IP, err
 	}
 
 	// When we got here, it means none of the available prefixes could allocate an address for us, meaning
-	// we need a new prefix - but this is NotYetImplemented for Anexia Kubernetes Service MVP
-	log.Info("no configured prefix was able to allocate an IP")
-	return nil, cloudprovider.NotImplemented
+	// we need a new prefix
+	mask := m.provisioning.NetworkMask(fam)
+	if mask == 0 {
+		log.Info("no configured prefix was able to allocate an IP")
+		return nil, cloudprovider.NotImplemented
+	}
+
+	// a prefix ordered before might not be tagged yet, it would neither be discovered nor keep us from ordering
+	// another one
+	if err := m.tagOrderedPrefixes(ctx, fam); err != nil {
+		return nil, err
+	}
+
+	// a prefix ordered before might not be usable yet, ordering another one for every retry would pile them up
+	if pending := m.pendingPrefixIdentifiers(fam); len(pending) > 0 {
+		return nil, fmt.Errorf("%w: %v", ErrPrefixPending, pending)
+	}
+
+	identifier, err := m.provisionPrefix(ctx, fam, mask)
+	if err != nil {
+		return nil, fmt.Errorf("error ordering prefix: %w", err)
+	}
+
+	return nil, fmt.Errorf("%w: ordered prefix %q", ErrPrefixPending, identifier)
+}
+
+// provisionPrefix orders a new prefix of the given family and network mask and tags it for auto discovery, returning
+// the identifier of the new prefix. When tagging fails, it is retried by tagOrderedPrefixes.
+func (m *mgr) provisionPrefix(ctx context.Context, fam v1.IPFamily, mask int) (string, error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	version := 4
+	if fam == v1.IPv6Protocol {
+		version = 6
+	}
+
+	create := anxprefix.Create{
+		Location:            m.provisioning.Location,
+		IPVersion:           version,
+		Type:                anxprefix.TypePublic,
+		NetworkMask:         mask,
+		CreateVLAN:          m.provisioning.VLAN == "",
+		VLANID:              m.provisioning.VLAN,
+		CustomerDescription: fmt.Sprintf("LoadBalancer prefix of Kubernetes cluster %s", *m.autoDiscoveryName),
+	}
+
+	summary, err := m.ipam.Prefix().Create(ctx, create)
+	if err != nil {
+		return "", err
+	}
+
+	log.Info("Ordered prefix for external IPs", "identifier", summary.ID, "family", fam, "network-mask", mask)
+
+	if m.untaggedPrefixes == nil {
+		m.untaggedPrefixes = make(map[v1.IPFamily][]string)
+	}
+
+	m.untaggedPrefixes[fam] = append(m.untaggedPrefixes[fam], summary.ID)
+
+	if err := m.tagOrderedPrefixes(ctx, fam); err != nil {
+		return "", err
+	}
+
+	return summary.ID, nil
+}
+
+// tagOrderedPrefixes tags the prefixes of the given family ordered by this manager but not tagged yet for auto
+// discovery, recording them as ordered and invalidating the prefix cache when successful. Prefixes failing to be
+// tagged are retried on the next call.
+func (m *mgr) tagOrderedPrefixes(ctx context.Context, fam v1.IPFamily) error {
+	if len(m.untaggedPrefixes[fam]) == 0 {
+		return nil
+	}
+
+	log := logr.FromContextOrDiscard(ctx)
+	tag := PrefixDiscoveryTag(*m.autoDiscoveryName)
+
+	var errs []error
+
+	m.untaggedPrefixes[fam] = slices.DeleteFunc(m.untaggedPrefixes[fam], func(identifier string) bool {
+		if err := m.api.Create(ctx, &corev1.ResourceWithTag{Identifier: identifier, Tag: tag}); err != nil {
+			log.Error(err, "Error tagging ordered prefix, retrying on next allocation", "identifier", identifier, "tag", tag)
+			errs = append(errs, fmt.Errorf("error tagging prefix %q: %w", identifier, err))
+			return false
+		}
+
+		if m.orderedPrefixes == nil {
+			m.orderedPrefixes = make(map[v1.IPFamily][]string)
+		}
+
+		m.orderedPrefixes[fam] = append(m.orderedPrefixes[fam], identifier)
+		m.prefixCache = nil
+
+		return true
+	})
+
+	return errors.Join(errs...)
+}
+
+// ValidateAnnotations checks the address related annotations of the Service the same way AllocateAddresses parses them.
+func ValidateAnnotations(svc *v1.Service) error {
+	_, err := serviceAddressFamilies(svc)
+	return err
 }
 
 func serviceAddressFamilies(svc *v1.Service) ([]v1.IPFamily, error) {
@@ -231,7 +412,7 @@ func serviceAddressFamilies(svc *v1.Service) ([]v1.IPFamily, error) {
 			}
 
 			if !valid {
-				return nil, fmt.Errorf("%w: %v is not a valid IPFamily", errInvalidIPFamiliesAnnotation, fam)
+				return nil, fmt.Errorf("%w: %v is not a valid IPFamily", ErrInvalidIPFamiliesAnnotation, fam)
 			}
 
 			families = append(families, v1.IPFamily(fam))
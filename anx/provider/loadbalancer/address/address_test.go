@@ -0,0 +1,201 @@
+package address
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "go.anx.io/go-anxcloud/pkg/apis/core/v1"
+	anxprefix "go.anx.io/go-anxcloud/pkg/ipam/prefix"
+	v1 "k8s.io/api/core/v1"
+	cloudprovider "k8s.io/cloud-provider"
+	"k8s.io/utils/ptr"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/configuration"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/test/apimock"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/test/legacyapimock"
+)
+
+var _ = Describe("mgr", func() {
+	var c *gomock.Controller
+	var ipamClient *legacyapimock.MockIPAMAPI
+	var prefixClient *legacyapimock.MockIPAMPrefixAPI
+	var genericClient *apimock.MockAPI
+	var m *mgr
+
+	BeforeEach(func() {
+		c = gomock.NewController(GinkgoT())
+		ipamClient = legacyapimock.NewMockIPAMAPI(c)
+		prefixClient = legacyapimock.NewMockIPAMPrefixAPI(c)
+		genericClient = apimock.NewMockAPI(c)
+		ipamClient.EXPECT().Prefix().AnyTimes().Return(anxprefix.API(prefixClient))
+
+		_, v4, _ := net.ParseCIDR("192.0.2.0/24")
+
+		// a fresh cache keeps the prefixes from being discovered
+		m = &mgr{
+			api:               genericClient,
+			ipam:              ipamClient,
+			logger:            logr.Discard(),
+			autoDiscoveryName: ptr.To("cluster"),
+			prefixCache: []*prefix{{
+				identifier: "v4-prefix",
+				prefix:     *v4,
+				family:     v1.IPv4Protocol,
+				addresses:  []net.IP{net.ParseIP("192.0.2.254")},
+			}},
+			prefixCacheTimestamp: time.Now(),
+		}
+	})
+
+	It("allocates from a discovered prefix of the family", func() {
+		m.provisioning = &configuration.PrefixProvisioning{Location: "location", IPv4NetworkMask: 28}
+
+		ip, err := m.allocateAddress(context.TODO(), v1.IPv4Protocol)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ip.String()).To(Equal("192.0.2.254"))
+	})
+
+	It("returns NotImplemented without prefix provisioning for the family", func() {
+		m.provisioning = &configuration.PrefixProvisioning{Location: "location", IPv4NetworkMask: 28}
+
+		_, err := m.allocateAddress(context.TODO(), v1.IPv6Protocol)
+		Expect(err).To(MatchError(cloudprovider.NotImplemented))
+	})
+
+	It("orders and tags a prefix when none of the family is discovered", func() {
+		m.provisioning = &configuration.PrefixProvisioning{Location: "location", IPv6NetworkMask: 64}
+
+		prefixClient.EXPECT().Create(gomock.Any(), anxprefix.Create{
+			Location:            "location",
+			IPVersion:           6,
+			Type:                anxprefix.TypePublic,
+			NetworkMask:         64,
+			CreateVLAN:          true,
+			CustomerDescription: "LoadBalancer prefix of Kubernetes cluster cluster",
+		}).Return(anxprefix.Summary{ID: "v6-prefix"}, nil)
+
+		genericClient.EXPECT().Create(gomock.Any(), &corev1.ResourceWithTag{
+			Identifier: "v6-prefix",
+			Tag:        "kubernetes-lb-prefix-cluster",
+		}).Return(nil)
+
+		_, err := m.allocateAddress(context.TODO(), v1.IPv6Protocol)
+		Expect(err).To(MatchError(ErrPrefixPending))
+		Expect(err).To(MatchError(ContainSubstring("v6-prefix")))
+		Expect(m.prefixCache).To(BeNil())
+	})
+
+	It("orders prefixes in the configured VLAN", func() {
+		m.provisioning = &configuration.PrefixProvisioning{Location: "location", VLAN: "vlan", IPv6NetworkMask: 64}
+
+		prefixClient.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, create anxprefix.Create) (anxprefix.Summary, error) {
+			Expect(create.VLANID).To(Equal("vlan"))
+			Expect(create.CreateVLAN).To(BeFalse())
+			return anxprefix.Summary{ID: "v6-prefix"}, nil
+		})
+
+		genericClient.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := m.allocateAddress(context.TODO(), v1.IPv6Protocol)
+		Expect(err).To(MatchError(ErrPrefixPending))
+	})
+
+	It("does not order another prefix while a discovered one is pending", func() {
+		m.provisioning = &configuration.PrefixProvisioning{Location: "location", IPv6NetworkMask: 64}
+		m.pendingPrefixes = map[v1.IPFamily][]string{v1.IPv6Protocol: {"v6-prefix"}}
+
+		_, err := m.allocateAddress(context.TODO(), v1.IPv6Protocol)
+		Expect(err).To(MatchError(ErrPrefixPending))
+	})
+
+	It("orders a prefix while only one of the other family is pending", func() {
+		m.provisioning = &configuration.PrefixProvisioning{Location: "location", IPv6NetworkMask: 64}
+		m.pendingPrefixes = map[v1.IPFamily][]string{v1.IPv4Protocol: {"broken-v4-prefix"}}
+
+		prefixClient.EXPECT().Create(gomock.Any(), gomock.Any()).Return(anxprefix.Summary{ID: "v6-prefix"}, nil)
+		genericClient.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := m.allocateAddress(context.TODO(), v1.IPv6Protocol)
+		Expect(err).To(MatchError(ContainSubstring("ordered prefix \"v6-prefix\"")))
+	})
+
+	It("does not order another prefix while the one it ordered is not usable yet", func() {
+		m.provisioning = &configuration.PrefixProvisioning{Location: "location", IPv6NetworkMask: 64}
+		cached := m.prefixCache
+
+		// only expected once
+		prefixClient.EXPECT().Create(gomock.Any(), gomock.Any()).Return(anxprefix.Summary{ID: "v6-prefix"}, nil)
+		genericClient.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := m.allocateAddress(context.TODO(), v1.IPv6Protocol)
+		Expect(err).To(MatchError(ErrPrefixPending))
+
+		// discovered again, but the ordered prefix is not usable yet
+		m.prefixCache = cached
+		m.prefixCacheTimestamp = time.Now()
+
+		_, err = m.allocateAddress(context.TODO(), v1.IPv6Protocol)
+		Expect(err).To(MatchError(ErrPrefixPending))
+		Expect(err).To(MatchError(ContainSubstring("v6-prefix")))
+	})
+
+	It("retries tagging an ordered prefix instead of ordering another one", func() {
+		m.provisioning = &configuration.PrefixProvisioning{Location: "location", IPv6NetworkMask: 64}
+
+		// only expected once
+		prefixClient.EXPECT().Create(gomock.Any(), gomock.Any()).Return(anxprefix.Summary{ID: "v6-prefix"}, nil)
+
+		tagged := &corev1.ResourceWithTag{Identifier: "v6-prefix", Tag: "kubernetes-lb-prefix-cluster"}
+		gomock.InOrder(
+			genericClient.EXPECT().Create(gomock.Any(), tagged).Return(errors.New("engine unavailable")),
+			genericClient.EXPECT().Create(gomock.Any(), tagged).Return(nil),
+		)
+
+		_, err := m.allocateAddress(context.TODO(), v1.IPv6Protocol)
+		Expect(err).To(MatchError(ContainSubstring("error tagging prefix \"v6-prefix\"")))
+		Expect(m.pendingPrefixIdentifiers(v1.IPv6Protocol)).To(BeEmpty())
+
+		_, err = m.allocateAddress(context.TODO(), v1.IPv6Protocol)
+		Expect(err).To(MatchError(ErrPrefixPending))
+		Expect(m.pendingPrefixIdentifiers(v1.IPv6Protocol)).To(Equal([]string{"v6-prefix"}))
+		Expect(m.untaggedPrefixes[v1.IPv6Protocol]).To(BeEmpty())
+	})
+
+	It("counts only discovered prefixes of known family as pending", func() {
+		m.prefixCache = nil
+
+		genericClient.EXPECT().List(gomock.Any(), &corev1.Resource{Tags: []string{PrefixDiscoveryTag("cluster")}}, gomock.Any()).
+			DoAndReturn(apimock.ListResources("broken-v4-prefix", "mistagged"))
+		prefixClient.EXPECT().Get(gomock.Any(), "mistagged").Return(anxprefix.Info{}, errors.New("not a prefix"))
+		prefixClient.EXPECT().Get(gomock.Any(), "broken-v4-prefix").Return(anxprefix.Info{Name: "198.51.100.0/29"}, nil)
+		genericClient.EXPECT().List(gomock.Any(), &corev1.Resource{Tags: []string{"kubernetes-lb-vip-cluster"}}, gomock.Any()).
+			Return(errors.New("engine unavailable"))
+
+		_, err := m.prefixes(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m.pendingPrefixIdentifiers(v1.IPv4Protocol)).To(Equal([]string{"broken-v4-prefix"}))
+		Expect(m.pendingPrefixIdentifiers(v1.IPv6Protocol)).To(BeEmpty())
+	})
+
+	It("allocates from an ordered prefix once it is usable", func() {
+		m.prefixCache = nil
+		m.orderedPrefixes = map[v1.IPFamily][]string{v1.IPv6Protocol: {"v6-prefix"}}
+
+		genericClient.EXPECT().List(gomock.Any(), &corev1.Resource{Tags: []string{PrefixDiscoveryTag("cluster")}}, gomock.Any()).
+			DoAndReturn(apimock.ListResources("v6-prefix"))
+		prefixClient.EXPECT().Get(gomock.Any(), "v6-prefix").Return(anxprefix.Info{Name: "2001:db8::/64"}, nil)
+		genericClient.EXPECT().List(gomock.Any(), &corev1.Resource{Tags: []string{"kubernetes-lb-vip-cluster"}}, gomock.Any()).
+			DoAndReturn(apimock.ListResources())
+
+		ip, err := m.allocateAddress(context.TODO(), v1.IPv6Protocol)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ip).NotTo(BeNil())
+		Expect(m.orderedPrefixes[v1.IPv6Protocol]).To(BeEmpty())
+	})
+})
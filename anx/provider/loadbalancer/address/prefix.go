@@ -11,15 +11,63 @@ import (
 	"go.anx.io/go-anxcloud/pkg/api"
 	"go.anx.io/go-anxcloud/pkg/api/types"
 	corev1 "go.anx.io/go-anxcloud/pkg/apis/core/v1"
+	"go.anx.io/go-anxcloud/pkg/client"
 	"go.anx.io/go-anxcloud/pkg/ipam"
 	v1 "k8s.io/api/core/v1"
 )
 
+// unusablePrefixError is returned for prefixes of known family not usable yet.
+type unusablePrefixError struct {
+	family v1.IPFamily
+	err    error
+}
+
+func (e *unusablePrefixError) Error() string {
+	return fmt.Sprintf("%s prefix not usable: %v", e.family, e.err)
+}
+
+func (e *unusablePrefixError) Unwrap() error {
+	return e.err
+}
+
 type prefix struct {
 	identifier string
 	prefix     net.IPNet
 	family     v1.IPFamily
 	addresses  []net.IP
+
+	// true when the address was discovered via its VIP tag instead of being calculated
+	vipDiscovered bool
+}
+
+// PrefixInfo describes a prefix as it is used for allocating external IP addresses.
+type PrefixInfo struct {
+	Identifier string
+	Prefix     net.IPNet
+	Family     v1.IPFamily
+
+	// Address is the external IP address allocated from this prefix
+	Address net.IP
+
+	// AddressDiscovered is true when Address was discovered via its VIP tag, false when it was calculated
+	AddressDiscovered bool
+}
+
+// DescribePrefix retrieves the prefix with the given identifier the same way a Manager does, without allocating
+// anything. The VIP is discovered via tag when autoDiscoveryName is not nil, like for NewWithAutoDiscovery.
+func DescribePrefix(ctx context.Context, apiClient api.API, legacyClient client.Client, identifier string, autoDiscoveryName *string) (PrefixInfo, error) {
+	p, err := newPrefix(ctx, apiClient, ipam.NewAPI(legacyClient), identifier, autoDiscoveryName)
+	if err != nil {
+		return PrefixInfo{}, err
+	}
+
+	return PrefixInfo{
+		Identifier:        p.identifier,
+		Prefix:            p.prefix,
+		Family:            p.family,
+		Address:           p.addresses[0],
+		AddressDiscovered: p.vipDiscovered,
+	}, nil
 }
 
 func newPrefix(ctx context.Context, apiclient api.API, ipamClient ipam.API, identifier string, autoDiscoveryName *string) (*prefix, error) {
@@ -47,9 +95,11 @@ func newPrefix(ctx context.Context, apiclient api.API, ipamClient ipam.API, iden
 		tag := fmt.Sprintf("kubernetes-lb-vip-%s", *autoDiscoveryName)
 		vip, err := ret.discoverVIP(ctx, apiclient, ipamClient, tag)
 		if err != nil {
-			return nil, fmt.Errorf("error discovering VIP: %w", err)
+			return nil, &unusablePrefixError{family: ret.family, err: fmt.Errorf("error discovering VIP: %w", err)}
 		}
 
+		ret.vipDiscovered = vip != nil
+
 		if vip == nil {
 			// Fall back for backwards compatibility - remove once there are no autodiscovery clusters without tagged VIPs
 			vip = calculateVIP(ret.prefix)
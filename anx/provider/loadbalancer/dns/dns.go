@@ -0,0 +1,325 @@
+// Package dns maintains Anexia CloudDNS records pointing to the external addresses of LoadBalancer Services.
+//
+// Records are owned through a TXT record next to them, containing an ownership marker naming the Service. Only
+// records at names with the ownership marker of a Service are changed or removed for it, names already used by
+// records not owned by the Service are left alone.
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"go.anx.io/go-anxcloud/pkg/api"
+	"go.anx.io/go-anxcloud/pkg/api/types"
+	clouddnsv1 "go.anx.io/go-anxcloud/pkg/apis/clouddns/v1"
+)
+
+const (
+	recordTypeA    = "A"
+	recordTypeAAAA = "AAAA"
+	recordTypeTXT  = "TXT"
+
+	// apexName is the record name of the zone apex
+	apexName = "@"
+
+	ownershipMarkerPrefix = "heritage=anexia-ccm,owner="
+
+	// recordsCacheTTL is how long the listed records of the zone are reused for planning, saving a listing of the
+	// whole zone for every Service in sync
+	recordsCacheTTL = 30 * time.Second
+)
+
+var (
+	// ErrNameOutsideZone is returned for names not inside the configured zone
+	ErrNameOutsideZone = errors.New("name is not inside the configured CloudDNS zone")
+
+	// ErrNameNotOwned is returned for names already having records not owned by the Service
+	ErrNameNotOwned = errors.New("name already has records not owned by this Service")
+)
+
+// Manager maintains records in a single CloudDNS zone.
+//
+// The records of the zone are listed at most every recordsCacheTTL as long as no changes are necessary. Changes are
+// always planned on a fresh listing, after which the cached records are discarded.
+type Manager struct {
+	api  api.API
+	zone string
+	ttl  int
+
+	recordsMu       sync.Mutex
+	records         []clouddnsv1.Record
+	recordsListedAt time.Time
+}
+
+// New creates a Manager for records in the given zone, created with the given TTL.
+func New(apiClient api.API, zone string, ttl int) *Manager {
+	return &Manager{
+		api:  apiClient,
+		zone: normalizeName(zone),
+		ttl:  ttl,
+	}
+}
+
+// Zone returns the name of the zone managed.
+func (m *Manager) Zone() string {
+	return m.zone
+}
+
+// Contains returns true if the given fully qualified name is inside the zone.
+func (m *Manager) Contains(name string) bool {
+	_, err := m.relativeName(name)
+	return err == nil
+}
+
+// Reconcile makes the A and AAAA records of the given names point to exactly the given addresses and removes records
+// at names owned by owner but no longer given. Passing no names removes all records owned by owner.
+func (m *Manager) Reconcile(ctx context.Context, owner string, names []string, addresses []net.IP) error {
+	logger := logr.FromContextOrDiscard(ctx).WithValues("zone", m.zone, "owner", owner)
+
+	records, cached, err := m.cachedRecords(ctx)
+	if err != nil {
+		return err
+	}
+
+	toCreate, toDestroy, planErr := m.plan(records, owner, names, addresses)
+
+	if len(toCreate) == 0 && len(toDestroy) == 0 {
+		return planErr
+	}
+
+	// the records changed below are not the cached ones anymore
+	defer m.discardRecords()
+
+	if cached {
+		records, err = m.listRecords(ctx)
+		if err != nil {
+			return err
+		}
+
+		toCreate, toDestroy, planErr = m.plan(records, owner, names, addresses)
+	}
+
+	// records are destroyed first, making sure a name is never pointing to outdated and current addresses at once
+	for _, record := range toDestroy {
+		logger.Info("Deleting CloudDNS record", "name", record.Name, "type", record.Type, "rdata", record.RData)
+
+		// not found means someone else already deleted it
+		if err := api.IgnoreNotFound(m.api.Destroy(ctx, &record)); err != nil {
+			return fmt.Errorf("error deleting %s record %q: %w", record.Type, record.Name, err)
+		}
+	}
+
+	for _, record := range toCreate {
+		logger.Info("Creating CloudDNS record", "name", record.Name, "type", record.Type, "rdata", record.RData)
+
+		if err := m.api.Create(ctx, &record); err != nil {
+			return fmt.Errorf("error creating %s record %q: %w", record.Type, record.Name, err)
+		}
+	}
+
+	return planErr
+}
+
+// cachedRecords returns the records of the zone, listing them if not listed in the last recordsCacheTTL. The returned
+// bool is true when the records were taken from the cache.
+func (m *Manager) cachedRecords(ctx context.Context) ([]clouddnsv1.Record, bool, error) {
+	m.recordsMu.Lock()
+	defer m.recordsMu.Unlock()
+
+	if m.records != nil && time.Since(m.recordsListedAt) < recordsCacheTTL {
+		return m.records, true, nil
+	}
+
+	records, err := m.listRecords(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	m.records = records
+	m.recordsListedAt = time.Now()
+
+	return records, false, nil
+}
+
+// discardRecords discards the cached records, making the next reconciliation list them again.
+func (m *Manager) discardRecords() {
+	m.recordsMu.Lock()
+	defer m.recordsMu.Unlock()
+
+	m.records = nil
+}
+
+func (m *Manager) listRecords(ctx context.Context) ([]clouddnsv1.Record, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var oc types.ObjectChannel
+	if err := m.api.List(ctx, &clouddnsv1.Record{ZoneName: m.zone}, api.ObjectChannel(&oc)); err != nil {
+		return nil, fmt.Errorf("error listing records of CloudDNS zone %q: %w", m.zone, err)
+	}
+
+	ret := make([]clouddnsv1.Record, 0)
+	for retriever := range oc {
+		var record clouddnsv1.Record
+		if err := retriever(&record); err != nil {
+			return nil, fmt.Errorf("error retrieving CloudDNS record: %w", err)
+		}
+
+		record.ZoneName = m.zone
+		ret = append(ret, record)
+	}
+
+	return ret, nil
+}
+
+// plan compares the existing records to the desired names and addresses, returning the records to create and destroy.
+// Names that cannot be managed are skipped and reported in the returned error, without affecting other names.
+func (m *Manager) plan(records []clouddnsv1.Record, owner string, names []string, addresses []net.IP) ([]clouddnsv1.Record, []clouddnsv1.Record, error) {
+	marker := ownershipMarker(owner)
+
+	byName := make(map[string][]clouddnsv1.Record)
+	for _, record := range records {
+		name := strings.ToLower(record.Name)
+		if name == "" {
+			name = apexName
+		}
+
+		byName[name] = append(byName[name], record)
+	}
+
+	desired := make([]string, 0, len(names))
+	errs := make([]error, 0)
+
+	for _, name := range names {
+		relative, err := m.relativeName(name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%q: %w", name, err))
+			continue
+		}
+
+		if !slices.Contains(desired, relative) {
+			desired = append(desired, relative)
+		}
+	}
+
+	var toCreate, toDestroy []clouddnsv1.Record
+
+	// names owned but no longer desired
+	for name, existing := range byName {
+		if slices.Contains(desired, name) || !slices.ContainsFunc(existing, isMarker(marker)) {
+			continue
+		}
+
+		for _, record := range existing {
+			if isAddressRecord(record) || isMarker(marker)(record) {
+				toDestroy = append(toDestroy, record)
+			}
+		}
+	}
+
+	for _, name := range desired {
+		existing := byName[name]
+
+		if !slices.ContainsFunc(existing, isMarker(marker)) {
+			if slices.ContainsFunc(existing, isAddressRecord) || slices.ContainsFunc(existing, isMarker("")) {
+				errs = append(errs, fmt.Errorf("%q: %w", name, ErrNameNotOwned))
+				continue
+			}
+
+			toCreate = append(toCreate, m.record(name, recordTypeTXT, fmt.Sprintf("%q", marker)))
+		}
+
+		for _, record := range existing {
+			if isAddressRecord(record) && !slices.ContainsFunc(addresses, ipEqual(record.RData)) {
+				toDestroy = append(toDestroy, record)
+			}
+		}
+
+		for _, address := range addresses {
+			if slices.ContainsFunc(existing, func(record clouddnsv1.Record) bool {
+				return isAddressRecord(record) && ipEqual(record.RData)(address)
+			}) {
+				continue
+			}
+
+			recordType := recordTypeA
+			if address.To4() == nil {
+				recordType = recordTypeAAAA
+			}
+
+			toCreate = append(toCreate, m.record(name, recordType, address.String()))
+		}
+	}
+
+	return toCreate, toDestroy, errors.Join(errs...)
+}
+
+func (m *Manager) record(name, recordType, rdata string) clouddnsv1.Record {
+	return clouddnsv1.Record{
+		ZoneName: m.zone,
+		Name:     name,
+		Type:     recordType,
+		RData:    rdata,
+		TTL:      m.ttl,
+	}
+}
+
+// relativeName returns the record name of the given fully qualified name inside the zone.
+func (m *Manager) relativeName(name string) (string, error) {
+	name = normalizeName(name)
+
+	if name == m.zone {
+		return apexName, nil
+	}
+
+	if relative, found := strings.CutSuffix(name, "."+m.zone); found && relative != "" {
+		return relative, nil
+	}
+
+	return "", ErrNameOutsideZone
+}
+
+// ownershipMarker returns the content of the TXT record marking names owned by the given owner.
+func ownershipMarker(owner string) string {
+	return ownershipMarkerPrefix + owner
+}
+
+// isMarker returns a function matching TXT records with the given ownership marker or, if marker is empty, any
+// ownership marker.
+func isMarker(marker string) func(clouddnsv1.Record) bool {
+	return func(record clouddnsv1.Record) bool {
+		if record.Type != recordTypeTXT {
+			return false
+		}
+
+		content := strings.Trim(record.RData, `"`)
+
+		if marker == "" {
+			return strings.HasPrefix(content, ownershipMarkerPrefix)
+		}
+
+		return content == marker
+	}
+}
+
+func isAddressRecord(record clouddnsv1.Record) bool {
+	return record.Type == recordTypeA || record.Type == recordTypeAAAA
+}
+
+func ipEqual(address string) func(net.IP) bool {
+	ip := net.ParseIP(address)
+	return func(other net.IP) bool {
+		return ip.Equal(other)
+	}
+}
+
+func normalizeName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
@@ -0,0 +1,175 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.anx.io/go-anxcloud/pkg/api/types"
+	clouddnsv1 "go.anx.io/go-anxcloud/pkg/apis/clouddns/v1"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/test/apimock"
+)
+
+func TestDNS(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CloudDNS records")
+}
+
+var _ = Describe("plan", func() {
+	const owner = "cluster/default/web"
+
+	var m *Manager
+	addresses := []net.IP{net.ParseIP("192.0.2.1"), net.ParseIP("2001:db8::1")}
+
+	marker := func(name, owner string) clouddnsv1.Record {
+		return clouddnsv1.Record{Identifier: "txt-" + name, Name: name, Type: "TXT", RData: `"` + ownershipMarker(owner) + `"`}
+	}
+
+	address := func(name, recordType, rdata string) clouddnsv1.Record {
+		return clouddnsv1.Record{Identifier: recordType + "-" + name + "-" + rdata, Name: name, Type: recordType, RData: rdata}
+	}
+
+	BeforeEach(func() {
+		m = New(nil, "Example.com.", 300)
+	})
+
+	It("creates ownership marker and address records for new names", func() {
+		toCreate, toDestroy, err := m.plan(nil, owner, []string{"web.example.com", "example.com."}, addresses)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(toDestroy).To(BeEmpty())
+		Expect(toCreate).To(ConsistOf(
+			m.record("web", "TXT", `"heritage=anexia-ccm,owner=cluster/default/web"`),
+			m.record("web", "A", "192.0.2.1"),
+			m.record("web", "AAAA", "2001:db8::1"),
+			m.record("@", "TXT", `"heritage=anexia-ccm,owner=cluster/default/web"`),
+			m.record("@", "A", "192.0.2.1"),
+			m.record("@", "AAAA", "2001:db8::1"),
+		))
+	})
+
+	It("does nothing for records in sync", func() {
+		records := []clouddnsv1.Record{
+			marker("web", owner),
+			address("web", "A", "192.0.2.1"),
+			address("web", "AAAA", "2001:db8::1"),
+		}
+
+		toCreate, toDestroy, err := m.plan(records, owner, []string{"web.example.com"}, addresses)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(toCreate).To(BeEmpty())
+		Expect(toDestroy).To(BeEmpty())
+	})
+
+	It("replaces records of changed addresses", func() {
+		records := []clouddnsv1.Record{
+			marker("web", owner),
+			address("web", "A", "192.0.2.99"),
+			address("web", "AAAA", "2001:db8::1"),
+		}
+
+		toCreate, toDestroy, err := m.plan(records, owner, []string{"web.example.com"}, addresses)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(toCreate).To(ConsistOf(m.record("web", "A", "192.0.2.1")))
+		Expect(toDestroy).To(ConsistOf(records[1]))
+	})
+
+	It("removes all owned records of names no longer given", func() {
+		records := []clouddnsv1.Record{
+			marker("web", owner),
+			address("web", "A", "192.0.2.1"),
+			marker("other", "cluster/default/other"),
+			address("other", "A", "192.0.2.2"),
+			address("unmanaged", "A", "192.0.2.3"),
+		}
+
+		toCreate, toDestroy, err := m.plan(records, owner, nil, nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(toCreate).To(BeEmpty())
+		Expect(toDestroy).To(ConsistOf(records[0], records[1]))
+	})
+
+	It("does not take over names with records not owned", func() {
+		records := []clouddnsv1.Record{
+			address("unmanaged", "A", "192.0.2.3"),
+			marker("other", "cluster/default/other"),
+		}
+
+		toCreate, toDestroy, err := m.plan(records, owner, []string{"unmanaged.example.com", "other.example.com", "web.example.com"}, addresses[:1])
+
+		Expect(err).To(MatchError(ErrNameNotOwned))
+		Expect(err).To(MatchError(ContainSubstring("unmanaged")))
+		Expect(err).To(MatchError(ContainSubstring("other")))
+		Expect(toDestroy).To(BeEmpty())
+		Expect(toCreate).To(ConsistOf(
+			m.record("web", "TXT", `"heritage=anexia-ccm,owner=cluster/default/web"`),
+			m.record("web", "A", "192.0.2.1"),
+		))
+	})
+
+	It("rejects names outside the zone", func() {
+		_, _, err := m.plan(nil, owner, []string{"web.example.org", "notexample.com"}, addresses)
+
+		Expect(err).To(MatchError(ErrNameOutsideZone))
+	})
+})
+
+var _ = Describe("Reconcile", func() {
+	const owner = "cluster/default/web"
+
+	var genericClient *apimock.MockAPI
+	var m *Manager
+
+	records := []clouddnsv1.Record{
+		{Identifier: "txt-web", ZoneName: "example.com", Name: "web", Type: "TXT", RData: `"` + ownershipMarker(owner) + `"`},
+		{Identifier: "a-web", ZoneName: "example.com", Name: "web", Type: "A", RData: "192.0.2.1"},
+	}
+
+	expectListed := func() *gomock.Call {
+		return genericClient.EXPECT().List(gomock.Any(), &clouddnsv1.Record{ZoneName: "example.com"}, gomock.Any()).
+			DoAndReturn(apimock.ListObjects(records...))
+	}
+
+	BeforeEach(func() {
+		genericClient = apimock.NewMockAPI(gomock.NewController(GinkgoT()))
+		m = New(genericClient, "example.com", 300)
+	})
+
+	It("reuses the listed records while they are in sync", func() {
+		expectListed().Times(1)
+
+		for range 3 {
+			Expect(m.Reconcile(context.TODO(), owner, []string{"web.example.com"}, []net.IP{net.ParseIP("192.0.2.1")})).To(Succeed())
+		}
+	})
+
+	It("plans changes on freshly listed records and lists them again afterwards", func() {
+		expectListed().Times(3)
+		genericClient.EXPECT().Destroy(gomock.Any(), &records[1]).Return(nil)
+		genericClient.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, o types.Object, _ ...types.CreateOption) error {
+			Expect(o).To(Equal(&clouddnsv1.Record{ZoneName: "example.com", Name: "web", Type: "A", RData: "192.0.2.2", TTL: 300}))
+			return nil
+		})
+
+		Expect(m.Reconcile(context.TODO(), owner, []string{"web.example.com"}, []net.IP{net.ParseIP("192.0.2.1")})).To(Succeed())
+		Expect(m.Reconcile(context.TODO(), owner, []string{"web.example.com"}, []net.IP{net.ParseIP("192.0.2.2")})).To(Succeed())
+		Expect(m.Reconcile(context.TODO(), owner, []string{"web.example.com"}, []net.IP{net.ParseIP("192.0.2.1")})).To(Succeed())
+	})
+})
+
+var _ = DescribeTable("Contains",
+	func(name string, expected bool) {
+		Expect(New(nil, "example.com", 300).Contains(name)).To(Equal(expected))
+	},
+	Entry("apex", "example.com", true),
+	Entry("subdomain", "Web.Example.com.", true),
+	Entry("other zone", "web.example.org", false),
+	Entry("suffix without dot", "webexample.com", false),
+)
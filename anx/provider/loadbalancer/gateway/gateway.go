@@ -0,0 +1,639 @@
+// Package gateway implements a Gateway API controller backed by LBaaS. Every Gateway of a GatewayClass handled by the
+// CCM is reconciled like a LoadBalancer Service: its listeners become ports, forwarding to the NodePort of the backend
+// Service of the routes attached to them.
+//
+// LBaaS forwards connections on TCP level, a listener forwards all connections to a single backend. Matches, filters
+// and weights of routes are not supported.
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	cloudprovider "k8s.io/cloud-provider"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// Finalizer makes sure the LBaaS resources of a Gateway are removed before it is deleted
+	Finalizer = "lbaas.anx.io/gateway"
+
+	// serviceNameSuffix is appended to the Gateway name for the Service representing it. Service names cannot contain
+	// dots, the resources of a Gateway never collide with those of a Service this way.
+	serviceNameSuffix = ".gateway"
+)
+
+var (
+	errNoBackend       = errors.New("no attached route with a backend")
+	errRefNotPermitted = errors.New("only Services in the namespace of the route are supported as backends")
+	errBackendNotFound = errors.New("backend not found")
+	errNoNodePort      = errors.New("backend Service port has no NodePort")
+)
+
+// routeKinds maps the supported listener protocols to the kind of routes attachable to them
+var routeKinds = map[string]string{
+	protocolHTTP: kindHTTPRoute,
+	protocolTCP:  kindTCPRoute,
+}
+
+// serviceLookup returns the Service with the given namespace and name.
+type serviceLookup func(namespace, name string) (*v1.Service, error)
+
+// Config configures which Gateways are handled and how often they are synced.
+type Config struct {
+	// GatewayClasses with this controllerName are handled
+	ControllerName string
+
+	// name of the cluster passed to the LoadBalancer
+	ClusterName string
+
+	Interval time.Duration
+}
+
+// Controller reconciles Gateways of the handled GatewayClasses via a cloudprovider.LoadBalancer.
+type Controller struct {
+	config  Config
+	logger  logr.Logger
+	k8s     kubernetes.Interface
+	dynamic dynamic.Interface
+	lb      cloudprovider.LoadBalancer
+}
+
+// New creates a Controller reading Gateway API objects with dynamicClient, Services and Nodes with k8sClient and
+// reconciling Gateways with lb.
+func New(config Config, logger logr.Logger, k8sClient kubernetes.Interface, dynamicClient dynamic.Interface, lb cloudprovider.LoadBalancer) *Controller {
+	return &Controller{
+		config:  config,
+		logger:  logger,
+		k8s:     k8sClient,
+		dynamic: dynamicClient,
+		lb:      lb,
+	}
+}
+
+// Run syncs all GatewayClasses and Gateways in the configured interval until ctx is done.
+func (c *Controller) Run(ctx context.Context) {
+	wait.UntilWithContext(ctx, c.sync, c.config.Interval)
+}
+
+func (c *Controller) sync(ctx context.Context) {
+	classes, err := c.syncGatewayClasses(ctx)
+	if err != nil {
+		c.logger.Error(err, "Error syncing GatewayClasses")
+		return
+	}
+
+	gateways, err := list[gateway](ctx, c.dynamic.Resource(gatewaysResource))
+	if err != nil {
+		c.logger.Error(err, "Error listing Gateways")
+		return
+	}
+
+	routes, err := c.listRoutes(ctx)
+	if err != nil {
+		c.logger.Error(err, "Error listing routes")
+		return
+	}
+
+	nodes, err := c.loadBalancerNodes(ctx)
+	if err != nil {
+		c.logger.Error(err, "Error listing Nodes")
+		return
+	}
+
+	lookupService, err := c.listServices(ctx)
+	if err != nil {
+		c.logger.Error(err, "Error listing Services")
+		return
+	}
+
+	for i := range gateways {
+		gw := &gateways[i]
+
+		if err := c.syncGateway(ctx, gw, classes, routes, lookupService, nodes); err != nil {
+			c.logger.Error(err, "Error syncing Gateway", "namespace", gw.Namespace, "name", gw.Name)
+		}
+	}
+}
+
+// syncGatewayClasses accepts the GatewayClasses with the configured controllerName, returning their names.
+func (c *Controller) syncGatewayClasses(ctx context.Context) (sets.Set[string], error) {
+	classes, err := list[gatewayClass](ctx, c.dynamic.Resource(gatewayClassesResource))
+	if err != nil {
+		return nil, err
+	}
+
+	ret := sets.New[string]()
+
+	for _, class := range classes {
+		if class.Spec.ControllerName != c.config.ControllerName {
+			continue
+		}
+
+		ret.Insert(class.Name)
+
+		conditions := slices.Clone(class.Status.Conditions)
+		setCondition(&conditions, class.Generation, conditionAccepted, nil, reasonAccepted, "Handled by the Anexia cloud-controller-manager")
+
+		if equality.Semantic.DeepEqual(conditions, class.Status.Conditions) {
+			continue
+		}
+
+		status := map[string]any{"conditions": conditions}
+		if err := patchStatus(ctx, c.dynamic.Resource(gatewayClassesResource), class.Name, status); err != nil {
+			c.logger.Error(err, "Error updating status of GatewayClass", "name", class.Name)
+		}
+	}
+
+	return ret, nil
+}
+
+func (c *Controller) syncGateway(ctx context.Context, gw *gateway, classes sets.Set[string], routes []route, lookupService serviceLookup, nodes []*v1.Node) error {
+	logger := c.logger.WithValues("gateway", gw.Namespace+"/"+gw.Name)
+	ctx = logr.NewContext(ctx, logger)
+
+	handled := classes.Has(gw.Spec.GatewayClassName)
+	finalized := slices.Contains(gw.Finalizers, Finalizer)
+
+	if handled && gw.DeletionTimestamp == nil {
+		if !finalized {
+			if err := c.setFinalizer(ctx, gw, true); err != nil {
+				return fmt.Errorf("error adding finalizer: %w", err)
+			}
+		}
+
+		return c.ensureGateway(ctx, gw, routes, lookupService, nodes)
+	}
+
+	if !finalized {
+		return nil
+	}
+
+	// deleted or moved to a GatewayClass not handled by us
+	svc, _ := gatewayService(gw, nil, nil)
+	if svc.DeletionTimestamp == nil {
+		svc.DeletionTimestamp = ptr.To(metav1.Now())
+	}
+
+	if err := c.lb.EnsureLoadBalancerDeleted(ctx, c.config.ClusterName, svc); err != nil {
+		return fmt.Errorf("error removing LBaaS resources: %w", err)
+	}
+
+	logger.Info("Removed LBaaS resources of Gateway")
+
+	if err := c.setFinalizer(ctx, gw, false); err != nil {
+		return fmt.Errorf("error removing finalizer: %w", err)
+	}
+
+	return nil
+}
+
+// ensureGateway reconciles the LBaaS resources of the Gateway and updates its status.
+func (c *Controller) ensureGateway(ctx context.Context, gw *gateway, routes []route, lookupService serviceLookup, nodes []*v1.Node) error {
+	svc, listeners := gatewayService(gw, routes, lookupService)
+
+	lbStatus, lbErr := c.lb.EnsureLoadBalancer(ctx, c.config.ClusterName, svc, nodes)
+
+	status := gatewayStatus{
+		Addresses:  gw.Status.Addresses,
+		Conditions: slices.Clone(gw.Status.Conditions),
+		Listeners:  mergeListenerStatus(gw.Status.Listeners, listeners),
+	}
+
+	setCondition(&status.Conditions, gw.Generation, conditionAccepted, nil, reasonAccepted, "")
+
+	if lbErr != nil {
+		setCondition(&status.Conditions, gw.Generation, conditionProgrammed, lbErr, reasonPending, "")
+
+		// listeners not programmed for other reasons keep them
+		for i := range status.Listeners {
+			if !meta.IsStatusConditionTrue(status.Listeners[i].Conditions, conditionProgrammed) {
+				continue
+			}
+
+			setCondition(&status.Listeners[i].Conditions, gw.Generation, conditionProgrammed, lbErr, reasonPending, "")
+		}
+	} else {
+		status.Addresses = gatewayAddresses(lbStatus)
+		setCondition(&status.Conditions, gw.Generation, conditionProgrammed, nil, reasonProgrammed, "")
+	}
+
+	if !equality.Semantic.DeepEqual(status, gw.Status) {
+		patch := map[string]any{
+			"addresses":  status.Addresses,
+			"conditions": status.Conditions,
+			"listeners":  status.Listeners,
+		}
+
+		if err := patchStatus(ctx, c.dynamic.Resource(gatewaysResource).Namespace(gw.Namespace), gw.Name, patch); err != nil {
+			return errors.Join(lbErr, fmt.Errorf("error updating status: %w", err))
+		}
+	}
+
+	return lbErr
+}
+
+// gatewayService returns the Service representing the Gateway for the LoadBalancer, with a port for every listener
+// with a backend resolved, and the status of the listeners.
+func gatewayService(gw *gateway, routes []route, lookupService serviceLookup) (*v1.Service, []listenerStatus) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              gw.Name + serviceNameSuffix,
+			Namespace:         gw.Namespace,
+			UID:               gw.UID,
+			Annotations:       gw.Annotations,
+			DeletionTimestamp: gw.DeletionTimestamp,
+		},
+		Spec: v1.ServiceSpec{
+			Type:       v1.ServiceTypeLoadBalancer,
+			IPFamilies: []v1.IPFamily{v1.IPv4Protocol},
+		},
+	}
+
+	// addresses already allocated are kept
+	for _, address := range gw.Status.Addresses {
+		if address.Type == "" || address.Type == addressTypeIPAddress {
+			svc.Status.LoadBalancer.Ingress = append(svc.Status.LoadBalancer.Ingress, v1.LoadBalancerIngress{IP: address.Value})
+		}
+	}
+
+	listeners := make([]listenerStatus, 0, len(gw.Spec.Listeners))
+
+	for _, l := range gw.Spec.Listeners {
+		status, port := resolveListener(gw, l, routes, lookupService)
+		listeners = append(listeners, status)
+
+		if port != nil {
+			svc.Spec.Ports = append(svc.Spec.Ports, *port)
+		}
+	}
+
+	return svc, listeners
+}
+
+// resolveListener returns the status of the listener and, when its backend is resolved, the Service port for it.
+func resolveListener(gw *gateway, l listener, routes []route, lookupService serviceLookup) (listenerStatus, *v1.ServicePort) {
+	status := listenerStatus{
+		Name:           l.Name,
+		SupportedKinds: []routeGroupKind{},
+		Conditions:     []metav1.Condition{},
+	}
+
+	kind, ok := routeKinds[l.Protocol]
+	if !ok {
+		err := fmt.Errorf("protocol %s is not supported, only HTTP and TCP are", l.Protocol)
+		setCondition(&status.Conditions, gw.Generation, conditionAccepted, err, reasonUnsupportedProtocol, "")
+		setCondition(&status.Conditions, gw.Generation, conditionResolvedRefs, nil, reasonResolvedRefs, "")
+		setCondition(&status.Conditions, gw.Generation, conditionProgrammed, err, reasonInvalid, "")
+
+		return status, nil
+	}
+
+	status.SupportedKinds = append(status.SupportedKinds, routeGroupKind{Group: groupName, Kind: kind})
+
+	attached := attachedRoutes(gw, l, kind, routes)
+	status.AttachedRoutes = int32(len(attached))
+
+	setCondition(&status.Conditions, gw.Generation, conditionAccepted, nil, reasonAccepted, "")
+
+	nodePort, err := backendNodePort(attached, lookupService)
+
+	switch {
+	case errors.Is(err, errRefNotPermitted):
+		setCondition(&status.Conditions, gw.Generation, conditionResolvedRefs, err, reasonRefNotPermitted, "")
+	case errors.Is(err, errBackendNotFound), errors.Is(err, errNoNodePort):
+		setCondition(&status.Conditions, gw.Generation, conditionResolvedRefs, err, reasonBackendNotFound, "")
+	default:
+		setCondition(&status.Conditions, gw.Generation, conditionResolvedRefs, nil, reasonResolvedRefs, "")
+	}
+
+	switch {
+	case errors.Is(err, errNoBackend):
+		setCondition(&status.Conditions, gw.Generation, conditionProgrammed, err, reasonPending, "")
+		return status, nil
+	case err != nil:
+		setCondition(&status.Conditions, gw.Generation, conditionProgrammed, err, reasonInvalid, "")
+		return status, nil
+	}
+
+	setCondition(&status.Conditions, gw.Generation, conditionProgrammed, nil, reasonProgrammed, "")
+
+	return status, &v1.ServicePort{
+		Name:     l.Name,
+		Protocol: v1.ProtocolTCP,
+		Port:     l.Port,
+		NodePort: nodePort,
+	}
+}
+
+// attachedRoutes returns the routes of the given kind attached to the listener, oldest first.
+func attachedRoutes(gw *gateway, l listener, kind string, routes []route) []route {
+	ret := make([]route, 0)
+
+	for _, r := range routes {
+		if r.Kind != kind || !namespaceAllowed(gw, l, r.Namespace) {
+			continue
+		}
+
+		if slices.ContainsFunc(r.Spec.ParentRefs, func(ref parentReference) bool {
+			return referencesListener(ref, r.Namespace, gw, l)
+		}) {
+			ret = append(ret, r)
+		}
+	}
+
+	// the oldest route wins on conflicts, like everywhere in the Gateway API
+	slices.SortStableFunc(ret, func(a, b route) int {
+		if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+			if a.CreationTimestamp.Before(&b.CreationTimestamp) {
+				return -1
+			}
+
+			return 1
+		}
+
+		return strings.Compare(a.Namespace+"/"+a.Name, b.Namespace+"/"+b.Name)
+	})
+
+	return ret
+}
+
+// namespaceAllowed returns true if routes in the given namespace may attach to the listener. Selecting namespaces by
+// label is not supported and handled like the default, allowing routes in the namespace of the Gateway.
+func namespaceAllowed(gw *gateway, l listener, namespace string) bool {
+	from := namespacesFromSame
+	if l.AllowedRoutes != nil && l.AllowedRoutes.Namespaces != nil && l.AllowedRoutes.Namespaces.From != "" {
+		from = l.AllowedRoutes.Namespaces.From
+	}
+
+	return from == namespacesFromAll || namespace == gw.Namespace
+}
+
+func referencesListener(ref parentReference, routeNamespace string, gw *gateway, l listener) bool {
+	return ptr.Deref(ref.Group, groupName) == groupName &&
+		ptr.Deref(ref.Kind, kindGateway) == kindGateway &&
+		ptr.Deref(ref.Namespace, routeNamespace) == gw.Namespace &&
+		ref.Name == gw.Name &&
+		(ref.SectionName == nil || *ref.SectionName == l.Name) &&
+		(ref.Port == nil || *ref.Port == l.Port)
+}
+
+// backendNodePort returns the NodePort of the first backend of the given routes. LBaaS forwards all connections of a
+// listener to a single backend, further backends are ignored.
+func backendNodePort(routes []route, lookupService serviceLookup) (int32, error) {
+	for _, r := range routes {
+		for _, rule := range r.Spec.Rules {
+			if len(rule.BackendRefs) > 0 {
+				return resolveBackend(r.Namespace, rule.BackendRefs[0], lookupService)
+			}
+		}
+	}
+
+	return 0, errNoBackend
+}
+
+func resolveBackend(routeNamespace string, ref backendReference, lookupService serviceLookup) (int32, error) {
+	if ptr.Deref(ref.Group, "") != "" || ptr.Deref(ref.Kind, kindService) != kindService {
+		return 0, fmt.Errorf("%w: %s is not a Service", errBackendNotFound, ref.Name)
+	}
+
+	if ptr.Deref(ref.Namespace, routeNamespace) != routeNamespace {
+		return 0, fmt.Errorf("%w: %s/%s", errRefNotPermitted, *ref.Namespace, ref.Name)
+	}
+
+	if ref.Port == nil {
+		return 0, fmt.Errorf("%w: no port given for Service %s/%s", errBackendNotFound, routeNamespace, ref.Name)
+	}
+
+	svc, err := lookupService(routeNamespace, ref.Name)
+	if err != nil {
+		return 0, fmt.Errorf("%w: Service %s/%s: %w", errBackendNotFound, routeNamespace, ref.Name, err)
+	}
+
+	for _, port := range svc.Spec.Ports {
+		if port.Port != *ref.Port {
+			continue
+		}
+
+		if port.NodePort == 0 {
+			return 0, fmt.Errorf("%w: Service %s/%s port %d", errNoNodePort, routeNamespace, ref.Name, port.Port)
+		}
+
+		return port.NodePort, nil
+	}
+
+	return 0, fmt.Errorf("%w: Service %s/%s has no port %d", errBackendNotFound, routeNamespace, ref.Name, *ref.Port)
+}
+
+// mergeListenerStatus returns the given listener status with the transition times of unchanged conditions taken from
+// the previous status.
+func mergeListenerStatus(previous, current []listenerStatus) []listenerStatus {
+	ret := make([]listenerStatus, 0, len(current))
+
+	for _, status := range current {
+		if i := slices.IndexFunc(previous, func(p listenerStatus) bool { return p.Name == status.Name }); i >= 0 {
+			conditions := slices.Clone(previous[i].Conditions)
+			for _, condition := range status.Conditions {
+				meta.SetStatusCondition(&conditions, condition)
+			}
+
+			// conditions no longer set are dropped
+			conditions = slices.DeleteFunc(conditions, func(condition metav1.Condition) bool {
+				return meta.FindStatusCondition(status.Conditions, condition.Type) == nil
+			})
+
+			status.Conditions = conditions
+		}
+
+		ret = append(ret, status)
+	}
+
+	return ret
+}
+
+// gatewayAddresses returns the Gateway status addresses for the given LoadBalancer status.
+func gatewayAddresses(status *v1.LoadBalancerStatus) []gatewayStatusAddress {
+	ret := make([]gatewayStatusAddress, 0)
+
+	if status == nil {
+		return ret
+	}
+
+	for _, ingress := range status.Ingress {
+		switch {
+		case ingress.IP != "":
+			ret = append(ret, gatewayStatusAddress{Type: addressTypeIPAddress, Value: ingress.IP})
+		case ingress.Hostname != "":
+			ret = append(ret, gatewayStatusAddress{Type: "Hostname", Value: ingress.Hostname})
+		}
+	}
+
+	return ret
+}
+
+// setCondition sets the condition of the given type, with status False and the error as message if err is not nil.
+// The reason is used for both cases, message only when err is nil.
+func setCondition(conditions *[]metav1.Condition, generation int64, conditionType string, err error, reason, message string) {
+	status := metav1.ConditionTrue
+	if err != nil {
+		status = metav1.ConditionFalse
+		message = err.Error()
+	}
+
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// listServices returns a serviceLookup for all Services in the cluster.
+func (c *Controller) listServices(ctx context.Context) (serviceLookup, error) {
+	services, err := c.k8s.CoreV1().Services("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[k8stypes.NamespacedName]*v1.Service, len(services.Items))
+	for i := range services.Items {
+		svc := &services.Items[i]
+		byName[k8stypes.NamespacedName{Namespace: svc.Namespace, Name: svc.Name}] = svc
+	}
+
+	return func(namespace, name string) (*v1.Service, error) {
+		if svc, ok := byName[k8stypes.NamespacedName{Namespace: namespace, Name: name}]; ok {
+			return svc, nil
+		}
+
+		return nil, apierrors.NewNotFound(v1.Resource("services"), name)
+	}, nil
+}
+
+// listRoutes returns all HTTPRoutes and TCPRoutes, skipping kinds not installed in the cluster.
+func (c *Controller) listRoutes(ctx context.Context) ([]route, error) {
+	ret := make([]route, 0)
+
+	for _, kind := range []struct {
+		name     string
+		resource schema.GroupVersionResource
+	}{
+		{kindHTTPRoute, httpRoutesResource},
+		{kindTCPRoute, tcpRoutesResource},
+	} {
+		routes, err := list[route](ctx, c.dynamic.Resource(kind.resource))
+		if apierrors.IsNotFound(err) {
+			c.logger.V(1).Info("Route kind not installed, skipping it", "kind", kind.name)
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("error listing %ss: %w", kind.name, err)
+		}
+
+		for i := range routes {
+			routes[i].Kind = kind.name
+		}
+
+		ret = append(ret, routes...)
+	}
+
+	return ret, nil
+}
+
+// loadBalancerNodes returns the ready Nodes not excluded from external LoadBalancers.
+func (c *Controller) loadBalancerNodes(ctx context.Context) ([]*v1.Node, error) {
+	nodes, err := c.k8s.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*v1.Node, 0, len(nodes.Items))
+
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+
+		if _, excluded := node.Labels[v1.LabelNodeExcludeBalancers]; excluded {
+			continue
+		}
+
+		if slices.ContainsFunc(node.Status.Conditions, func(condition v1.NodeCondition) bool {
+			return condition.Type == v1.NodeReady && condition.Status == v1.ConditionTrue
+		}) {
+			ret = append(ret, node)
+		}
+	}
+
+	return ret, nil
+}
+
+func (c *Controller) setFinalizer(ctx context.Context, gw *gateway, present bool) error {
+	finalizers := slices.DeleteFunc(slices.Clone(gw.Finalizers), func(finalizer string) bool {
+		return finalizer == Finalizer
+	})
+
+	if present {
+		finalizers = append(finalizers, Finalizer)
+	}
+
+	// the resourceVersion makes the patch fail when the finalizers were changed concurrently
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"finalizers":      finalizers,
+			"resourceVersion": gw.ResourceVersion,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.dynamic.Resource(gatewaysResource).Namespace(gw.Namespace).Patch(ctx, gw.Name, k8stypes.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+func patchStatus(ctx context.Context, resource dynamic.ResourceInterface, name string, status map[string]any) error {
+	patch, err := json.Marshal(map[string]any{"status": status})
+	if err != nil {
+		return err
+	}
+
+	_, err = resource.Patch(ctx, name, k8stypes.MergePatchType, patch, metav1.PatchOptions{}, "status")
+	return err
+}
+
+func list[T any](ctx context.Context, resource dynamic.ResourceInterface) ([]T, error) {
+	objects, err := resource.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]T, 0, len(objects.Items))
+
+	for _, object := range objects.Items {
+		var converted T
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &converted); err != nil {
+			return nil, fmt.Errorf("error converting %s/%s: %w", object.GetNamespace(), object.GetName(), err)
+		}
+
+		ret = append(ret, converted)
+	}
+
+	return ret, nil
+}
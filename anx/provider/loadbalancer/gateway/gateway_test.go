@@ -0,0 +1,285 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	cloudprovider "k8s.io/cloud-provider"
+	"k8s.io/utils/ptr"
+)
+
+func TestGateway(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Gateway API")
+}
+
+func testGateway(listeners ...listener) *gateway {
+	return &gateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", UID: "gateway-uid", Generation: 2},
+		Spec:       gatewaySpec{GatewayClassName: "anexia", Listeners: listeners},
+	}
+}
+
+func testRoute(kind, namespace, name string, parent parentReference, backends ...backendReference) route {
+	return route{
+		Kind:       kind,
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: routeSpec{
+			ParentRefs: []parentReference{parent},
+			Rules:      []routeRule{{BackendRefs: backends}},
+		},
+	}
+}
+
+func testLookup(services ...*v1.Service) serviceLookup {
+	return func(namespace, name string) (*v1.Service, error) {
+		for _, svc := range services {
+			if svc.Namespace == namespace && svc.Name == name {
+				return svc, nil
+			}
+		}
+
+		return nil, apierrors.NewNotFound(v1.Resource("services"), name)
+	}
+}
+
+var backendService = &v1.Service{
+	ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "backend"},
+	Spec: v1.ServiceSpec{
+		Ports: []v1.ServicePort{
+			{Name: "http", Port: 80, NodePort: 30080},
+			{Name: "metrics", Port: 9090},
+		},
+	},
+}
+
+var _ = Describe("gatewayService", func() {
+	It("represents the Gateway as Service with a port for every resolved listener", func() {
+		gw := testGateway(
+			listener{Name: "http", Port: 80, Protocol: protocolHTTP},
+			listener{Name: "tcp", Port: 8080, Protocol: protocolTCP},
+		)
+		gw.Annotations = map[string]string{"lbaas.anx.io/external-ip-families": "IPv6"}
+		gw.Status.Addresses = []gatewayStatusAddress{{Type: addressTypeIPAddress, Value: "192.0.2.1"}}
+
+		routes := []route{
+			testRoute(kindHTTPRoute, "default", "web", parentReference{Name: "web"}, backendReference{Name: "backend", Port: ptr.To[int32](80)}),
+			testRoute(kindTCPRoute, "default", "tcp", parentReference{Name: "web", SectionName: ptr.To("tcp")}, backendReference{Name: "backend", Port: ptr.To[int32](80)}),
+		}
+
+		svc, listeners := gatewayService(gw, routes, testLookup(backendService))
+
+		Expect(svc.Name).To(Equal("web.gateway"))
+		Expect(svc.Namespace).To(Equal("default"))
+		Expect(svc.UID).To(BeEquivalentTo("gateway-uid"))
+		Expect(svc.Annotations).To(Equal(gw.Annotations))
+		Expect(svc.Spec.Type).To(Equal(v1.ServiceTypeLoadBalancer))
+		Expect(svc.Status.LoadBalancer.Ingress).To(Equal([]v1.LoadBalancerIngress{{IP: "192.0.2.1"}}))
+		Expect(svc.Spec.Ports).To(Equal([]v1.ServicePort{
+			{Name: "http", Protocol: v1.ProtocolTCP, Port: 80, NodePort: 30080},
+			{Name: "tcp", Protocol: v1.ProtocolTCP, Port: 8080, NodePort: 30080},
+		}))
+
+		Expect(listeners).To(HaveLen(2))
+		for _, l := range listeners {
+			Expect(l.AttachedRoutes).To(BeEquivalentTo(1))
+			Expect(meta.IsStatusConditionTrue(l.Conditions, conditionProgrammed)).To(BeTrue())
+		}
+	})
+
+	It("reports listeners without usable backend", func() {
+		gw := testGateway(
+			listener{Name: "none", Port: 80, Protocol: protocolHTTP},
+			listener{Name: "other-namespace", Port: 81, Protocol: protocolHTTP},
+			listener{Name: "no-nodeport", Port: 82, Protocol: protocolHTTP},
+			listener{Name: "tls", Port: 443, Protocol: "TLS"},
+		)
+
+		routes := []route{
+			testRoute(kindHTTPRoute, "default", "other-namespace", parentReference{Name: "web", SectionName: ptr.To("other-namespace")},
+				backendReference{Name: "backend", Namespace: ptr.To("other"), Port: ptr.To[int32](80)}),
+			testRoute(kindHTTPRoute, "default", "no-nodeport", parentReference{Name: "web", Port: ptr.To[int32](82)},
+				backendReference{Name: "backend", Port: ptr.To[int32](9090)}),
+		}
+
+		svc, listeners := gatewayService(gw, routes, testLookup(backendService))
+
+		Expect(svc.Spec.Ports).To(BeEmpty())
+		Expect(meta.FindStatusCondition(listeners[0].Conditions, conditionProgrammed).Reason).To(Equal(reasonPending))
+		Expect(meta.FindStatusCondition(listeners[1].Conditions, conditionResolvedRefs).Reason).To(Equal(reasonRefNotPermitted))
+		Expect(meta.FindStatusCondition(listeners[2].Conditions, conditionProgrammed).Message).To(ContainSubstring("no NodePort"))
+		Expect(meta.FindStatusCondition(listeners[3].Conditions, conditionAccepted).Reason).To(Equal(reasonUnsupportedProtocol))
+		Expect(listeners[3].SupportedKinds).To(BeEmpty())
+	})
+})
+
+var _ = DescribeTable("resolveListener ResolvedRefs condition",
+	func(backend backendReference, status metav1.ConditionStatus, reason string) {
+		gw := testGateway(listener{Name: "http", Port: 80, Protocol: protocolHTTP})
+		routes := []route{testRoute(kindHTTPRoute, "default", "web", parentReference{Name: "web"}, backend)}
+
+		listenerStatus, _ := resolveListener(gw, gw.Spec.Listeners[0], routes, testLookup(backendService))
+
+		condition := meta.FindStatusCondition(listenerStatus.Conditions, conditionResolvedRefs)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(status))
+		Expect(condition.Reason).To(Equal(reason))
+	},
+	Entry("resolved Service", backendReference{Name: "backend", Port: ptr.To[int32](80)}, metav1.ConditionTrue, reasonResolvedRefs),
+	Entry("missing Service", backendReference{Name: "missing", Port: ptr.To[int32](80)}, metav1.ConditionFalse, reasonBackendNotFound),
+	Entry("not a Service", backendReference{Name: "backend", Kind: ptr.To("ConfigMap"), Port: ptr.To[int32](80)}, metav1.ConditionFalse, reasonBackendNotFound),
+	Entry("missing port", backendReference{Name: "backend", Port: ptr.To[int32](8080)}, metav1.ConditionFalse, reasonBackendNotFound),
+	Entry("port without NodePort", backendReference{Name: "backend", Port: ptr.To[int32](9090)}, metav1.ConditionFalse, reasonBackendNotFound),
+	Entry("Service in other namespace", backendReference{Name: "backend", Namespace: ptr.To("other"), Port: ptr.To[int32](80)}, metav1.ConditionFalse, reasonRefNotPermitted),
+)
+
+var _ = Describe("attachedRoutes", func() {
+	l := listener{Name: "http", Port: 80, Protocol: protocolHTTP}
+
+	It("attaches routes referencing the Gateway and listener", func() {
+		routes := []route{
+			testRoute(kindHTTPRoute, "default", "gateway", parentReference{Name: "web"}),
+			testRoute(kindHTTPRoute, "default", "section", parentReference{Name: "web", SectionName: ptr.To("http")}),
+			testRoute(kindHTTPRoute, "default", "other-section", parentReference{Name: "web", SectionName: ptr.To("https")}),
+			testRoute(kindHTTPRoute, "default", "other-gateway", parentReference{Name: "other"}),
+			testRoute(kindTCPRoute, "default", "other-kind", parentReference{Name: "web"}),
+			testRoute(kindHTTPRoute, "other", "other-namespace", parentReference{Name: "web", Namespace: ptr.To("default")}),
+		}
+
+		attached := attachedRoutes(testGateway(l), l, kindHTTPRoute, routes)
+
+		Expect(attached).To(HaveLen(2))
+		Expect(attached[0].Name).To(Equal("gateway"))
+		Expect(attached[1].Name).To(Equal("section"))
+	})
+
+	It("attaches routes of all namespaces when allowed", func() {
+		l := l
+		l.AllowedRoutes = &allowedRoutes{Namespaces: &routeNamespaces{From: namespacesFromAll}}
+
+		routes := []route{testRoute(kindHTTPRoute, "other", "other-namespace", parentReference{Name: "web", Namespace: ptr.To("default")})}
+
+		Expect(attachedRoutes(testGateway(l), l, kindHTTPRoute, routes)).To(HaveLen(1))
+	})
+})
+
+// fakeLoadBalancer records the Services it was called with
+type fakeLoadBalancer struct {
+	cloudprovider.LoadBalancer
+
+	ensured []*v1.Service
+	deleted []*v1.Service
+}
+
+func (f *fakeLoadBalancer) EnsureLoadBalancer(_ context.Context, _ string, svc *v1.Service, _ []*v1.Node) (*v1.LoadBalancerStatus, error) {
+	f.ensured = append(f.ensured, svc)
+	return &v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: "192.0.2.1"}}}, nil
+}
+
+func (f *fakeLoadBalancer) EnsureLoadBalancerDeleted(_ context.Context, _ string, svc *v1.Service) error {
+	f.deleted = append(f.deleted, svc)
+	return nil
+}
+
+var _ = Describe("Controller", func() {
+	var lb *fakeLoadBalancer
+	var dynamicClient *dynamicfake.FakeDynamicClient
+	var c *Controller
+
+	toUnstructured := func(gvr schema.GroupVersionResource, kind string, obj any) *unstructured.Unstructured {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		Expect(err).NotTo(HaveOccurred())
+
+		u := &unstructured.Unstructured{Object: content}
+		u.SetAPIVersion(gvr.GroupVersion().String())
+		u.SetKind(kind)
+		return u
+	}
+
+	getGateway := func() gateway {
+		u, err := dynamicClient.Resource(gatewaysResource).Namespace("default").Get(context.TODO(), "web", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		var gw gateway
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &gw)).To(Succeed())
+		return gw
+	}
+
+	BeforeEach(func() {
+		lb = &fakeLoadBalancer{}
+
+		class := gatewayClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "anexia"},
+			Spec:       gatewayClassSpec{ControllerName: "lbaas.anx.io/gateway-controller"},
+		}
+
+		gw := testGateway(listener{Name: "tcp", Port: 5432, Protocol: protocolTCP})
+		r := testRoute(kindTCPRoute, "default", "db", parentReference{Name: "web"}, backendReference{Name: "backend", Port: ptr.To[int32](80)})
+
+		dynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{
+				gatewayClassesResource: "GatewayClassList",
+				gatewaysResource:       "GatewayList",
+				httpRoutesResource:     "HTTPRouteList",
+				tcpRoutesResource:      "TCPRouteList",
+			},
+		)
+
+		// the fake client would guess "gatewaies" as resource for objects passed to its constructor
+		Expect(dynamicClient.Tracker().Create(gatewayClassesResource, toUnstructured(gatewayClassesResource, "GatewayClass", &class), "")).To(Succeed())
+		Expect(dynamicClient.Tracker().Create(gatewaysResource, toUnstructured(gatewaysResource, kindGateway, gw), "default")).To(Succeed())
+		Expect(dynamicClient.Tracker().Create(tcpRoutesResource, toUnstructured(tcpRoutesResource, kindTCPRoute, &r), "default")).To(Succeed())
+
+		k8sClient := fake.NewSimpleClientset(
+			backendService,
+			&v1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node"},
+				Status:     v1.NodeStatus{Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}},
+			},
+			&v1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "excluded", Labels: map[string]string{v1.LabelNodeExcludeBalancers: ""}},
+				Status:     v1.NodeStatus{Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}},
+			},
+		)
+
+		c = New(Config{ControllerName: "lbaas.anx.io/gateway-controller", ClusterName: "cluster"}, logr.Discard(), k8sClient, dynamicClient, lb)
+	})
+
+	It("reconciles Gateways of handled GatewayClasses", func() {
+		c.sync(context.TODO())
+
+		Expect(lb.ensured).To(HaveLen(1))
+		Expect(lb.ensured[0].Spec.Ports).To(Equal([]v1.ServicePort{{Name: "tcp", Protocol: v1.ProtocolTCP, Port: 5432, NodePort: 30080}}))
+
+		gw := getGateway()
+		Expect(gw.Finalizers).To(ContainElement(Finalizer))
+		Expect(gw.Status.Addresses).To(Equal([]gatewayStatusAddress{{Type: addressTypeIPAddress, Value: "192.0.2.1"}}))
+		Expect(meta.IsStatusConditionTrue(gw.Status.Conditions, conditionProgrammed)).To(BeTrue())
+		Expect(gw.Status.Listeners).To(HaveLen(1))
+		Expect(gw.Status.Listeners[0].AttachedRoutes).To(BeEquivalentTo(1))
+	})
+
+	It("removes the LBaaS resources of Gateways no longer handled", func() {
+		c.sync(context.TODO())
+
+		c.config.ControllerName = "example.com/other-controller"
+		c.sync(context.TODO())
+
+		Expect(lb.deleted).To(HaveLen(1))
+		Expect(lb.deleted[0].Name).To(Equal("web.gateway"))
+		Expect(lb.deleted[0].DeletionTimestamp).NotTo(BeNil())
+		Expect(getGateway().Finalizers).NotTo(ContainElement(Finalizer))
+	})
+})
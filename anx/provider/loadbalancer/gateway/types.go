@@ -0,0 +1,147 @@
+package gateway
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// The Gateway API types are not a dependency of the CCM, the following types mirror the fields of the upstream types
+// used by the controller. Objects are converted from and to unstructured ones retrieved via the dynamic client.
+
+const (
+	groupName = "gateway.networking.k8s.io"
+
+	kindGateway   = "Gateway"
+	kindService   = "Service"
+	kindHTTPRoute = "HTTPRoute"
+	kindTCPRoute  = "TCPRoute"
+
+	protocolHTTP = "HTTP"
+	protocolTCP  = "TCP"
+
+	namespacesFromAll  = "All"
+	namespacesFromSame = "Same"
+
+	addressTypeIPAddress = "IPAddress"
+
+	// condition types and reasons of GatewayClasses, Gateways and listeners
+	conditionAccepted     = "Accepted"
+	conditionProgrammed   = "Programmed"
+	conditionResolvedRefs = "ResolvedRefs"
+
+	reasonAccepted            = "Accepted"
+	reasonProgrammed          = "Programmed"
+	reasonResolvedRefs        = "ResolvedRefs"
+	reasonInvalid             = "Invalid"
+	reasonPending             = "Pending"
+	reasonUnsupportedProtocol = "UnsupportedProtocol"
+	reasonRefNotPermitted     = "RefNotPermitted"
+	reasonBackendNotFound     = "BackendNotFound"
+)
+
+var (
+	gatewayClassesResource = schema.GroupVersionResource{Group: groupName, Version: "v1", Resource: "gatewayclasses"}
+	gatewaysResource       = schema.GroupVersionResource{Group: groupName, Version: "v1", Resource: "gateways"}
+	httpRoutesResource     = schema.GroupVersionResource{Group: groupName, Version: "v1", Resource: "httproutes"}
+	tcpRoutesResource      = schema.GroupVersionResource{Group: groupName, Version: "v1alpha2", Resource: "tcproutes"}
+)
+
+type gatewayClass struct {
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec   gatewayClassSpec   `json:"spec"`
+	Status gatewayClassStatus `json:"status,omitempty"`
+}
+
+type gatewayClassSpec struct {
+	ControllerName string `json:"controllerName"`
+}
+
+type gatewayClassStatus struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+type gateway struct {
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec   gatewaySpec   `json:"spec"`
+	Status gatewayStatus `json:"status,omitempty"`
+}
+
+type gatewaySpec struct {
+	GatewayClassName string     `json:"gatewayClassName"`
+	Listeners        []listener `json:"listeners"`
+}
+
+type listener struct {
+	Name          string         `json:"name"`
+	Port          int32          `json:"port"`
+	Protocol      string         `json:"protocol"`
+	AllowedRoutes *allowedRoutes `json:"allowedRoutes,omitempty"`
+}
+
+type allowedRoutes struct {
+	Namespaces *routeNamespaces `json:"namespaces,omitempty"`
+}
+
+type routeNamespaces struct {
+	From string `json:"from,omitempty"`
+}
+
+type gatewayStatus struct {
+	Addresses  []gatewayStatusAddress `json:"addresses,omitempty"`
+	Conditions []metav1.Condition     `json:"conditions,omitempty"`
+	Listeners  []listenerStatus       `json:"listeners,omitempty"`
+}
+
+type gatewayStatusAddress struct {
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value"`
+}
+
+type listenerStatus struct {
+	Name           string             `json:"name"`
+	SupportedKinds []routeGroupKind   `json:"supportedKinds"`
+	AttachedRoutes int32              `json:"attachedRoutes"`
+	Conditions     []metav1.Condition `json:"conditions"`
+}
+
+type routeGroupKind struct {
+	Group string `json:"group,omitempty"`
+	Kind  string `json:"kind"`
+}
+
+// route is either a TCPRoute or an HTTPRoute, only their parents and backends are used
+type route struct {
+	Kind string `json:"kind"`
+
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec routeSpec `json:"spec"`
+}
+
+type routeSpec struct {
+	ParentRefs []parentReference `json:"parentRefs,omitempty"`
+	Rules      []routeRule       `json:"rules,omitempty"`
+}
+
+type parentReference struct {
+	Group       *string `json:"group,omitempty"`
+	Kind        *string `json:"kind,omitempty"`
+	Namespace   *string `json:"namespace,omitempty"`
+	Name        string  `json:"name"`
+	SectionName *string `json:"sectionName,omitempty"`
+	Port        *int32  `json:"port,omitempty"`
+}
+
+type routeRule struct {
+	BackendRefs []backendReference `json:"backendRefs,omitempty"`
+}
+
+type backendReference struct {
+	Group     *string `json:"group,omitempty"`
+	Kind      *string `json:"kind,omitempty"`
+	Name      string  `json:"name"`
+	Namespace *string `json:"namespace,omitempty"`
+	Port      *int32  `json:"port,omitempty"`
+}
@@ -0,0 +1,143 @@
+// Package inventory counts the LBaaS resources configured on a LoadBalancer by type and state.
+package inventory
+
+import (
+	"context"
+	"fmt"
+
+	"go.anx.io/go-anxcloud/pkg/api"
+	"go.anx.io/go-anxcloud/pkg/api/types"
+	"go.anx.io/go-anxcloud/pkg/apis/common/gs"
+	lbaasv1 "go.anx.io/go-anxcloud/pkg/apis/lbaas/v1"
+)
+
+// types of LBaaS resources
+const (
+	TypeLoadBalancer = "loadbalancer"
+	TypeFrontend     = "frontend"
+	TypeBackend      = "backend"
+	TypeBind         = "bind"
+	TypeServer       = "server"
+)
+
+// states of LBaaS resources, Updating resources are reported OK by the Engine but counted separately here
+const (
+	StateOK       = "ok"
+	StatePending  = "pending"
+	StateUpdating = "updating"
+	StateError    = "error"
+)
+
+var (
+	// Types lists all types of LBaaS resources counted.
+	Types = []string{TypeLoadBalancer, TypeFrontend, TypeBackend, TypeBind, TypeServer}
+
+	// States lists all states LBaaS resources are counted in.
+	States = []string{StateOK, StatePending, StateUpdating, StateError}
+)
+
+// Key identifies a counter of LBaaS resources.
+type Key struct {
+	Type  string
+	State string
+}
+
+// Counts holds the number of LBaaS resources by type and state, missing keys mean no resources.
+type Counts map[Key]int
+
+func (c Counts) add(resourceType string, hs gs.HasState) {
+	c[Key{Type: resourceType, State: State(hs)}]++
+}
+
+// State returns the state a LBaaS resource is counted in.
+func State(hs gs.HasState) string {
+	switch {
+	// Updating (ID 0) is classified as an OK state by the Engine
+	case hs.State.ID == lbaasv1.Updating.ID:
+		return StateUpdating
+	case hs.StateError():
+		return StateError
+	case hs.StatePending():
+		return StatePending
+	default:
+		return StateOK
+	}
+}
+
+// Collect counts the LoadBalancer with the given identifier and the Frontends, Backends, Binds and Servers configured
+// on it. Every resource is retrieved, this takes a request per resource.
+func Collect(ctx context.Context, apiClient api.API, loadBalancer string) (Counts, error) {
+	counts := Counts{}
+
+	lb := lbaasv1.LoadBalancer{Identifier: loadBalancer}
+	if err := apiClient.Get(ctx, &lb); err != nil {
+		return nil, fmt.Errorf("error retrieving LoadBalancer: %w", err)
+	}
+
+	counts.add(TypeLoadBalancer, lb.HasState)
+
+	frontends, err := list(ctx, apiClient, &lbaasv1.Frontend{LoadBalancer: &lbaasv1.LoadBalancer{Identifier: loadBalancer}})
+	if err != nil {
+		return nil, fmt.Errorf("error listing Frontends: %w", err)
+	}
+
+	for _, frontend := range frontends {
+		counts.add(TypeFrontend, frontend.HasState)
+
+		binds, err := list(ctx, apiClient, &lbaasv1.Bind{Frontend: lbaasv1.Frontend{Identifier: frontend.Identifier}})
+		if err != nil {
+			return nil, fmt.Errorf("error listing Binds of Frontend %q: %w", frontend.Identifier, err)
+		}
+
+		for _, bind := range binds {
+			counts.add(TypeBind, bind.HasState)
+		}
+	}
+
+	backends, err := list(ctx, apiClient, &lbaasv1.Backend{LoadBalancer: lbaasv1.LoadBalancer{Identifier: loadBalancer}})
+	if err != nil {
+		return nil, fmt.Errorf("error listing Backends: %w", err)
+	}
+
+	for _, backend := range backends {
+		counts.add(TypeBackend, backend.HasState)
+
+		servers, err := list(ctx, apiClient, &lbaasv1.Server{Backend: lbaasv1.Backend{Identifier: backend.Identifier}})
+		if err != nil {
+			return nil, fmt.Errorf("error listing Servers of Backend %q: %w", backend.Identifier, err)
+		}
+
+		for _, server := range servers {
+			counts.add(TypeServer, server.HasState)
+		}
+	}
+
+	return counts, nil
+}
+
+// list retrieves all objects matching the given filter object, which also defines the type of the returned objects.
+func list[T any, PT interface {
+	*T
+	types.FilterObject
+}](ctx context.Context, apiClient api.API, filter PT) ([]*T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var oc types.ObjectChannel
+	if err := apiClient.List(ctx, filter, api.ObjectChannel(&oc), api.FullObjects(true)); err != nil {
+		return nil, err
+	}
+
+	ret := make([]*T, 0)
+
+	for retriever := range oc {
+		var object T
+		if err := retriever(PT(&object)); err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, &object)
+	}
+
+	return ret, nil
+}
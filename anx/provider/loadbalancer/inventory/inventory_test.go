@@ -0,0 +1,39 @@
+package inventory
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.anx.io/go-anxcloud/pkg/apis/common/gs"
+	lbaasv1 "go.anx.io/go-anxcloud/pkg/apis/lbaas/v1"
+)
+
+func TestInventory(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "LBaaS resource inventory")
+}
+
+var _ = DescribeTable("State",
+	func(state gs.State, expected string) {
+		Expect(State(gs.HasState{State: state})).To(Equal(expected))
+	},
+	Entry("ok", gs.State{ID: "2", Type: gs.StateTypeOK}, StateOK),
+	Entry("updating", lbaasv1.Updating, StateUpdating),
+	Entry("pending", gs.State{ID: "3", Type: gs.StateTypePending}, StatePending),
+	Entry("error", gs.State{ID: "4", Type: gs.StateTypeError}, StateError),
+)
+
+var _ = Describe("Counts", func() {
+	It("counts resources by type and state", func() {
+		counts := Counts{}
+		counts.add(TypeServer, gs.HasState{State: gs.State{ID: "2", Type: gs.StateTypeOK}})
+		counts.add(TypeServer, gs.HasState{State: gs.State{ID: "4", Type: gs.StateTypeError}})
+		counts.add(TypeServer, gs.HasState{State: gs.State{ID: "2", Type: gs.StateTypeOK}})
+
+		Expect(counts).To(Equal(Counts{
+			{Type: TypeServer, State: StateOK}:    2,
+			{Type: TypeServer, State: StateError}: 1,
+		}))
+	})
+})
@@ -9,13 +9,20 @@ import (
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
 
 	cloudprovider "k8s.io/cloud-provider"
 	cloudproviderapi "k8s.io/cloud-provider/api"
@@ -23,8 +30,11 @@ import (
 	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/configuration"
 	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/loadbalancer/address"
 	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/loadbalancer/discovery"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/loadbalancer/dns"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/loadbalancer/provisioning"
 	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/loadbalancer/reconciliation"
 	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/metrics"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/tracing"
 
 	"go.anx.io/go-anxcloud/pkg/api"
 	"go.anx.io/go-anxcloud/pkg/client"
@@ -37,14 +47,48 @@ type mgr struct {
 	clusterName  string
 	k8s          kubernetes.Interface
 
+	// recorder records events on Services, nil without kubernetes client
+	recorder record.EventRecorder
+
+	// state holds everything that can be changed at runtime via Reload
+	state *atomic.Pointer[lbState]
+	sync  *sync.Mutex
+
+	metrics metrics.ProviderMetrics
+}
+
+// lbState is the part of the LoadBalancer manager configuration that can be swapped at runtime.
+type lbState struct {
 	addressManager address.Manager
+	loadBalancers  []string
+	backoffSteps   int
 
-	loadBalancers []string
-	sync          *sync.Mutex
+	// primaryLoadBalancer is the source of truth for the status of services, empty when using auto discovery
+	primaryLoadBalancer string
 
-	backoffSteps int
+	// discoveryTag is the tag LoadBalancers are discovered with, empty when auto discovery is disabled
+	discoveryTag      string
+	discoveryInterval time.Duration
 
-	metrics metrics.ProviderMetrics
+	// pendingDrains are LoadBalancers no longer discovered and not drained successfully yet, retried on every
+	// rediscovery
+	pendingDrains []string
+
+	// stateInterval is the interval the states of LBaaS resources are collected in, 0 disables collection
+	stateInterval time.Duration
+
+	// dns maintains CloudDNS records for Services, nil when no CloudDNS zone is configured
+	dns *dns.Manager
+
+	// dnsProxyPassHostname enables CloudDNS records for the AKEAnnotationHostname annotation
+	dnsProxyPassHostname bool
+}
+
+// Reloader is implemented by LoadBalancer managers able to apply a changed configuration at runtime.
+type Reloader interface {
+	// Reload applies the LoadBalancer related settings of the given, already validated configuration,
+	// keeping the previous configuration when they cannot be applied.
+	Reload(ctx context.Context, config *configuration.ProviderConfig) error
 }
 
 var (
@@ -54,6 +98,9 @@ var (
 	// ErrPortNameNotUnique is returned when asked to reconcile a service with non-unique port names
 	ErrPortNameNotUnique = errors.New("port name not unique")
 
+	// ErrInvalidHostname is returned for hostnames in lbaas.anx.io annotations not being valid RFC 1123 subdomains
+	ErrInvalidHostname = errors.New("invalid hostname")
+
 	// ErrNoUsableNodeAddress is returned when asked to reconcile a Service for set of Nodes from which at least one does not have a usable address.
 	ErrNoUsableNodeAddress = errors.New("node lacks usable address")
 
@@ -67,6 +114,21 @@ var (
 	//
 	// [KEP #1860]: https://github.com/kubernetes/enhancements/issues/1860
 	AKEAnnotationHostname = "lbaas.anx.io/load-balancer-proxy-pass-hostname"
+
+	// AnnotationDNSNames lists the names, separated by commas, CloudDNS records pointing to the external addresses of
+	// the Service are maintained for. All names have to be inside the configured CloudDNS zone.
+	AnnotationDNSNames = "lbaas.anx.io/dns-names"
+
+	// ErrNoDNSZone is reported when asked to maintain CloudDNS records without a CloudDNS zone configured.
+	ErrNoDNSZone = errors.New("CloudDNS records requested via annotation, but no cloudDNSZone configured")
+
+	// eventReasonDNSNamesNotManaged is the reason of events recorded on Services for names CloudDNS records cannot
+	// be maintained for.
+	eventReasonDNSNamesNotManaged = "DNSNamesNotManaged"
+
+	// annotationRequeuedAt is set on LoadBalancer Services to make the service controller reconcile them again,
+	// for example after the configuration was reloaded.
+	annotationRequeuedAt = "lbaas.anx.io/requeued-at"
 )
 
 // New creates a new LoadBalancer manager for the given Anexia generic client, cluster name and identifier of the
@@ -81,24 +143,83 @@ func New(config *configuration.ProviderConfig, logger logr.Logger, k8sClient kub
 		legacyClient: legacyClient,
 		k8s:          k8sClient,
 		logger:       logger,
+		state:        &atomic.Pointer[lbState]{},
 		sync:         &sync.Mutex{},
 		metrics:      providerMetrics,
-		backoffSteps: config.LoadBalancerBackoffSteps,
 	}
 
 	m.clusterName = config.ClusterName
 
+	if k8sClient != nil {
+		broadcaster := record.NewBroadcaster()
+		broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: k8sClient.CoreV1().Events("")})
+		m.recorder = broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: "anexia-ccm"})
+	}
+
 	ctx := logr.NewContext(context.TODO(), logger)
 
-	if err := m.configureLoadBalancers(ctx, config); err != nil {
+	state, err := m.configure(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	m.state.Store(state)
+
+	return &m, nil
+}
+
+// Reload resolves the LBaaS LoadBalancers and prefixes for the given configuration and, when successful, swaps
+// them in for all following reconciliations. Every LoadBalancer Service is requeued afterwards, to have it
+// reconciled with the new configuration. When resolving fails, the previous configuration stays active and
+// the error is returned.
+func (m *mgr) Reload(ctx context.Context, config *configuration.ProviderConfig) error {
+	state, err := m.configure(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	// wait for a running reconciliation to finish before swapping the configuration under it
+	m.sync.Lock()
+	state.pendingDrains = slices.DeleteFunc(slices.Clone(m.state.Load().pendingDrains), func(lb string) bool {
+		return slices.Contains(state.loadBalancers, lb)
+	})
+	m.state.Store(state)
+	m.sync.Unlock()
+
+	m.logger.Info("LoadBalancer configuration reloaded",
+		"loadbalancers", state.loadBalancers,
+		"backoff-steps", state.backoffSteps,
+	)
+
+	if err := m.requeueServices(ctx); err != nil {
+		m.logger.Error(err, "Error requeueing LoadBalancer Services after reload, they will be reconciled on their next change")
+	}
+
+	return nil
+}
+
+// configure builds a new lbState from the given configuration.
+func (m mgr) configure(ctx context.Context, config *configuration.ProviderConfig) (*lbState, error) {
+	state := lbState{
+		backoffSteps:      config.LoadBalancerBackoffSteps,
+		discoveryInterval: config.LoadBalancerDiscoveryInterval,
+		stateInterval:     config.LoadBalancerStateInterval,
+	}
+
+	if err := m.configureLoadBalancers(ctx, config, &state); err != nil {
 		return nil, fmt.Errorf("error configuring LoadBalancers: %w", err)
 	}
 
-	if err := m.configurePrefixes(ctx, config); err != nil {
+	if err := m.configurePrefixes(ctx, config, &state); err != nil {
 		return nil, fmt.Errorf("error configuring LoadBalancer Prefixes: %w", err)
 	}
 
-	return &m, nil
+	if config.CloudDNSZone != "" {
+		state.dns = dns.New(m.api, config.CloudDNSZone, config.CloudDNSRecordTTL)
+		state.dnsProxyPassHostname = config.CloudDNSProxyPassHostname
+	}
+
+	return &state, nil
 }
 
 // handleRateLimitError is converting a rate limit error returned by the Anexia Engine into
@@ -162,12 +283,43 @@ func (m mgr) GetLoadBalancer(ctx context.Context, clusterName string, service *v
 }
 
 func (m mgr) EnsureLoadBalancer(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*v1.LoadBalancerStatus, error) {
+	ctx, span := tracing.Start(ctx, "EnsureLoadBalancer", append(serviceAttributes(service), attribute.Int("nodes", len(nodes)))...)
+	status, err := m.ensureLoadBalancer(ctx, clusterName, service, nodes, service.DeletionTimestamp != nil)
+	tracing.End(span, err)
+
+	return status, err
+}
+
+func (m mgr) UpdateLoadBalancer(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) error {
+	_, err := m.EnsureLoadBalancer(ctx, clusterName, service, nodes)
+	return err
+}
+
+func (m mgr) EnsureLoadBalancerDeleted(ctx context.Context, clusterName string, service *v1.Service) error {
+	ctx, span := tracing.Start(ctx, "EnsureLoadBalancerDeleted", serviceAttributes(service)...)
+	_, err := m.ensureLoadBalancer(ctx, clusterName, service, []*v1.Node{}, true)
+	tracing.End(span, err)
+
+	return err
+}
+
+// serviceAttributes returns the attributes identifying the given Service on spans.
+func serviceAttributes(service *v1.Service) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("k8s.namespace.name", service.Namespace),
+		attribute.String("k8s.service.name", service.Name),
+	}
+}
+
+// ensureLoadBalancer reconciles the LBaaS resources and CloudDNS records of the given Service. When deleted is set,
+// all CloudDNS records and the metrics labelled with the Service are removed.
+func (m mgr) ensureLoadBalancer(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node, deleted bool) (*v1.LoadBalancerStatus, error) {
 	m.sync.Lock()
 	defer m.sync.Unlock()
 
 	ctx, clusterName = m.prepare(ctx, clusterName, service)
 
-	recon, _, err := m.reconciliationForService(ctx, clusterName, service, nodes)
+	recon, externalAddresses, err := m.reconciliationForService(ctx, clusterName, service, nodes)
 	if err != nil {
 		return nil, handleRateLimitError(err)
 	}
@@ -181,20 +333,96 @@ func (m mgr) EnsureLoadBalancer(ctx context.Context, clusterName string, service
 		return nil, handleRateLimitError(err)
 	}
 
+	var dnsNames []string
+	if !deleted {
+		dnsNames = m.serviceDNSNames(ctx, service)
+	}
+
+	if err := m.reconcileDNS(ctx, clusterName, service, dnsNames, externalAddresses); err != nil {
+		return nil, handleRateLimitError(err)
+	}
+
+	if deleted {
+		m.metrics.ForgetService(service.Namespace, service.Name)
+	} else {
+		m.metrics.ForService(service.Namespace, service.Name).MarkReconciled()
+	}
+
 	return status, nil
 }
 
-func (m mgr) UpdateLoadBalancer(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) error {
-	_, err := m.EnsureLoadBalancer(ctx, clusterName, service, nodes)
+// reconcileDNS maintains the CloudDNS records of the given Service for the given names and addresses. Names that
+// cannot be managed, e.g. because they already have records not owned by the Service or no CloudDNS zone is
+// configured, are reported in a warning event on the Service but do not fail the reconciliation, since retrying does
+// not help.
+func (m mgr) reconcileDNS(ctx context.Context, clusterName string, service *v1.Service, names []string, addresses []net.IP) error {
+	manager := m.state.Load().dns
+	if manager == nil {
+		if len(names) > 0 {
+			logr.FromContextOrDiscard(ctx).Error(ErrNoDNSZone, "Not maintaining CloudDNS records", "names", names)
+
+			if m.recorder != nil {
+				m.recorder.Eventf(service, v1.EventTypeWarning, eventReasonDNSNamesNotManaged,
+					"Not maintaining CloudDNS records for %s: %v", strings.Join(names, ", "), ErrNoDNSZone)
+			}
+		}
+
+		return nil
+	}
+
+	owner := strings.Join([]string{clusterName, service.Namespace, service.Name}, "/")
+
+	err := manager.Reconcile(ctx, owner, names, addresses)
+	if errors.Is(err, dns.ErrNameNotOwned) || errors.Is(err, dns.ErrNameOutsideZone) {
+		logr.FromContextOrDiscard(ctx).Error(err, "Not maintaining CloudDNS records for some names", "zone", manager.Zone())
+
+		if m.recorder != nil {
+			m.recorder.Eventf(service, v1.EventTypeWarning, eventReasonDNSNamesNotManaged,
+				"Not maintaining CloudDNS records in zone %s for some names: %v", manager.Zone(), err)
+		}
+
+		return nil
+	}
+
 	return err
 }
 
-func (m mgr) EnsureLoadBalancerDeleted(ctx context.Context, clusterName string, service *v1.Service) error {
-	_, err := m.EnsureLoadBalancer(ctx, clusterName, service, []*v1.Node{})
-	return err
+// serviceDNSNames returns the names CloudDNS records are maintained for the given Service: the ones given in its
+// AnnotationDNSNames annotation and, if enabled, the AKEAnnotationHostname when it is inside the CloudDNS zone.
+func (m mgr) serviceDNSNames(ctx context.Context, service *v1.Service) []string {
+	ret := annotatedDNSNames(service)
+
+	state := m.state.Load()
+	hostname := strings.ToLower(service.Annotations[AKEAnnotationHostname])
+
+	if hostname == "" || !state.dnsProxyPassHostname || state.dns == nil {
+		return ret
+	}
+
+	if !state.dns.Contains(hostname) {
+		logr.FromContextOrDiscard(ctx).V(1).Info("Proxy pass hostname not inside CloudDNS zone, not maintaining records for it",
+			"hostname", hostname, "zone", state.dns.Zone(),
+		)
+		return ret
+	}
+
+	return append(ret, hostname)
 }
 
-func (m *mgr) configureLoadBalancers(ctx context.Context, config *configuration.ProviderConfig) error {
+// annotatedDNSNames returns the names given in the AnnotationDNSNames annotation of the Service.
+func annotatedDNSNames(service *v1.Service) []string {
+	ret := make([]string, 0)
+
+	for _, name := range strings.Split(service.Annotations[AnnotationDNSNames], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			ret = append(ret, name)
+		}
+	}
+
+	return ret
+}
+
+func (m mgr) configureLoadBalancers(ctx context.Context, config *configuration.ProviderConfig, state *lbState) error {
 	if config.AutoDiscoverLoadBalancer {
 		tag := fmt.Sprintf("%s-%s", config.AutoDiscoveryTagPrefix, m.clusterName)
 		lbs, err := discovery.DiscoverLoadBalancers(ctx, m.api, tag)
@@ -202,33 +430,111 @@ func (m *mgr) configureLoadBalancers(ctx context.Context, config *configuration.
 			return err
 		}
 
-		m.loadBalancers = lbs
-	} else {
-		m.loadBalancers = []string{config.LoadBalancerIdentifier}
+		if config.LoadBalancerProvisioning != nil {
+			lbs, err = m.provisionLoadBalancers(ctx, tag, lbs, *config.LoadBalancerProvisioning)
+			if err != nil {
+				return fmt.Errorf("error provisioning LoadBalancers: %w", err)
+			}
+		}
+
+		state.loadBalancers = lbs
+		state.discoveryTag = tag
+	} else if config.LoadBalancerIdentifier != "" {
+		state.primaryLoadBalancer = config.LoadBalancerIdentifier
+		state.loadBalancers = []string{config.LoadBalancerIdentifier}
+
+		for _, lb := range config.SecondaryLoadBalancerIdentifiers {
+			if lb != "" && !slices.Contains(state.loadBalancers, lb) {
+				state.loadBalancers = append(state.loadBalancers, lb)
+			}
+		}
 	}
 
-	if len(m.loadBalancers) == 0 {
+	if len(state.loadBalancers) == 0 {
 		return ErrNoLoadBalancers
 	}
 
 	return nil
 }
 
-func (m *mgr) configurePrefixes(ctx context.Context, config *configuration.ProviderConfig) error {
+// provisionLoadBalancers provisions the configured LoadBalancers when none are discovered or some were provisioned
+// before, completing a partially provisioned set. It returns the discovered and provisioned LoadBalancers.
+func (m mgr) provisionLoadBalancers(ctx context.Context, tag string, discovered []string, config configuration.LoadBalancerProvisioning) ([]string, error) {
+	provisioned, err := provisioning.Provisioned(ctx, m.api, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(discovered) > 0 && len(provisioned) == 0 {
+		return discovered, nil
+	}
+
+	m.logger.V(1).Info("Provisioning LoadBalancers", "tag", tag, "discovered", discovered, "provisioned", provisioned)
+
+	lbs, err := provisioning.Provision(ctx, m.api, tag, m.clusterName, config)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, lb := range discovered {
+		if !slices.Contains(lbs, lb) {
+			lbs = append(lbs, lb)
+		}
+	}
+
+	return lbs, nil
+}
+
+func (m mgr) configurePrefixes(ctx context.Context, config *configuration.ProviderConfig, state *lbState) error {
 	if prefixes := config.LoadBalancerPrefixIdentifiers; len(prefixes) > 0 {
 		am, err := address.NewWithPrefixes(ctx, m.api, m.legacyClient, prefixes)
 		if err != nil {
 			return err
 		}
 
-		m.addressManager = am
+		state.addressManager = am
 	} else if config.AutoDiscoverLoadBalancer {
-		m.addressManager = address.NewWithAutoDiscovery(ctx, m.api, m.legacyClient, m.clusterName)
+		state.addressManager = address.NewWithAutoDiscovery(ctx, m.api, m.legacyClient, m.clusterName, config.PrefixProvisioning)
 	}
 
 	return nil
 }
 
+// requeueServices annotates every LoadBalancer Service in the cluster with the current time, making the service
+// controller reconcile all of them again. Failing to requeue one Service does not stop requeueing the others, all
+// errors are returned joined.
+func (m mgr) requeueServices(ctx context.Context) error {
+	if m.k8s == nil {
+		m.logger.Error(nil, "no usable kubernetes client to requeue LoadBalancer Services")
+		return nil
+	}
+
+	svcList, err := m.k8s.CoreV1().Services("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing services to requeue: %w", err)
+	}
+
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, annotationRequeuedAt, time.Now().UTC().Format(time.RFC3339))
+
+	var errs []error
+
+	for _, svc := range svcList.Items {
+		if svc.Spec.Type != v1.ServiceTypeLoadBalancer {
+			continue
+		}
+
+		_, err := m.k8s.CoreV1().Services(svc.Namespace).Patch(ctx, svc.Name, k8stypes.MergePatchType, []byte(patch), metav1.PatchOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error requeueing service %s/%s: %w", svc.Namespace, svc.Name, err))
+			continue
+		}
+
+		m.logger.V(1).Info("Requeued LoadBalancer Service", "service", fmt.Sprintf("%s/%s", svc.Namespace, svc.Name))
+	}
+
+	return errors.Join(errs...)
+}
+
 // prepare extends the context with a logger and checks if the cluster name is overriden for this manager.
 func (m mgr) prepare(ctx context.Context, clusterName string, svc *v1.Service) (context.Context, string) {
 	logger := m.logger.WithValues(
@@ -263,23 +569,14 @@ func (m mgr) reconciliationForService(ctx context.Context, clusterName string, s
 	var servers []reconciliation.Server
 	var externalAddresses []net.IP
 
-	if svc.DeletionTimestamp == nil {
-		ports = make(map[string]reconciliation.Port, len(svc.Spec.Ports))
-		for _, port := range svc.Spec.Ports {
-			if prevPort, ok := ports[port.Name]; ok {
-				m.logger.Error(
-					ErrPortNameNotUnique, "Port name not unique",
-					"port-name", port.Name,
-					"previous-port", prevPort.External,
-					"current-port", port.Port,
-				)
-				return nil, nil, ErrPortNameNotUnique
-			}
+	state := m.state.Load()
 
-			ports[port.Name] = reconciliation.Port{
-				Internal: uint16(port.NodePort),
-				External: uint16(port.Port),
-			}
+	if svc.DeletionTimestamp == nil {
+		var err error
+		ports, err = servicePorts(svc)
+		if err != nil {
+			m.logger.Error(err, "Port name not unique")
+			return nil, nil, err
 		}
 
 		servers = make([]reconciliation.Server, 0, len(nodes))
@@ -295,7 +592,9 @@ func (m mgr) reconciliationForService(ctx context.Context, clusterName string, s
 			})
 		}
 
-		ea, err := m.addressManager.AllocateAddresses(ctx, svc)
+		allocateCtx, span := tracing.Start(ctx, "AllocateAddresses")
+		ea, err := state.addressManager.AllocateAddresses(allocateCtx, svc)
+		tracing.End(span, err)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -319,8 +618,10 @@ func (m mgr) reconciliationForService(ctx context.Context, clusterName string, s
 		externalAddresses = make([]net.IP, 0)
 	}
 
-	mrecon := reconciliation.Multi()
-	for _, lb := range m.loadBalancers {
+	recons := make([]reconciliation.Reconciliation, 0, len(state.loadBalancers))
+	loadBalancerByRecon := make(map[reconciliation.Reconciliation]string, len(state.loadBalancers))
+
+	for _, lb := range state.loadBalancers {
 		ctx := logr.NewContext(
 			ctx,
 			logr.FromContextOrDiscard(ctx).WithValues(
@@ -340,14 +641,35 @@ func (m mgr) reconciliationForService(ctx context.Context, clusterName string, s
 			ports,
 			servers,
 
-			m.backoffSteps,
+			state.backoffSteps,
 
-			m.metrics,
+			m.metrics.ForService(svc.Namespace, svc.Name),
 		)
 		if err != nil {
 			return nil, nil, err
 		}
 
+		recons = append(recons, recon)
+		loadBalancerByRecon[recon] = lb
+	}
+
+	if state.primaryLoadBalancer == "" {
+		return reconciliation.Multi(recons...), externalAddresses, nil
+	}
+
+	// statically configured LoadBalancers always start with the primary one
+	mrecon := reconciliation.MultiWithPrimary(recons[0], func(secondary reconciliation.Reconciliation, err error) {
+		lb := loadBalancerByRecon[secondary]
+
+		if err != nil {
+			logr.FromContextOrDiscard(ctx).Error(err, "Secondary LoadBalancer not in sync with primary", "loadbalancer", lb)
+			m.metrics.LoadBalancerSecondaryInSync.WithLabelValues(lb).Set(0)
+		} else {
+			m.metrics.LoadBalancerSecondaryInSync.WithLabelValues(lb).Set(1)
+		}
+	})
+
+	for _, recon := range recons[1:] {
 		mrecon.Add(recon)
 	}
 
@@ -454,6 +776,61 @@ func lbStatusFromReconcileStatus(ipPortMap map[string][]uint16, service *v1.Serv
 	return &v1.LoadBalancerStatus{Ingress: ingresses}
 }
 
+// servicePorts returns the ports of the Service by name, failing with ErrPortNameNotUnique for duplicate names.
+func servicePorts(svc *v1.Service) (map[string]reconciliation.Port, error) {
+	ports := make(map[string]reconciliation.Port, len(svc.Spec.Ports))
+
+	for _, port := range svc.Spec.Ports {
+		if prevPort, ok := ports[port.Name]; ok {
+			return nil, fmt.Errorf("%w: %q used for ports %d and %d", ErrPortNameNotUnique, port.Name, prevPort.External, port.Port)
+		}
+
+		ports[port.Name] = reconciliation.Port{
+			Internal: uint16(port.NodePort),
+			External: uint16(port.Port),
+		}
+	}
+
+	return ports, nil
+}
+
+// ValidateService checks the Service for problems otherwise only found when reconciling it: invalid lbaas.anx.io
+// annotations and non-unique port names. All problems found are returned together.
+func ValidateService(svc *v1.Service) error {
+	errs := make([]error, 0)
+
+	if _, err := servicePorts(svc); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := address.ValidateAnnotations(svc); err != nil {
+		errs = append(errs, err)
+	}
+
+	if hostname := svc.Annotations[AKEAnnotationHostname]; hostname != "" {
+		if err := validateHostname(hostname); err != nil {
+			errs = append(errs, fmt.Errorf("annotation %v: %w", AKEAnnotationHostname, err))
+		}
+	}
+
+	for _, name := range annotatedDNSNames(svc) {
+		if err := validateHostname(strings.TrimSuffix(name, ".")); err != nil {
+			errs = append(errs, fmt.Errorf("annotation %v: %w", AnnotationDNSNames, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateHostname checks the hostname to be a valid RFC 1123 subdomain, ignoring case like it is when used.
+func validateHostname(hostname string) error {
+	if msgs := validation.IsDNS1123Subdomain(strings.ToLower(hostname)); len(msgs) > 0 {
+		return fmt.Errorf("%w %q: %s", ErrInvalidHostname, hostname, strings.Join(msgs, ", "))
+	}
+
+	return nil
+}
+
 func getNodeEndpointAddress(n *v1.Node) (net.IP, error) {
 	// XXX: assumes a node has one internal and one external IP, does funny things when a nodes has multiple of a given type
 	var internalIP, externalIP net.IP
@@ -1,22 +1,39 @@
 package loadbalancer
 
 import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
 	"testing"
 
 	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/configuration"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/loadbalancer/address"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/loadbalancer/dns"
 	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/metrics"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/test/apimock"
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"go.anx.io/go-anxcloud/pkg/api"
+	clouddnsv1 "go.anx.io/go-anxcloud/pkg/apis/clouddns/v1"
 	"go.anx.io/go-anxcloud/pkg/client"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 )
 
 var _ = Describe("Initialization", func() {
 	It("should initialize loadbalancer", func() {
 		config := configuration.ProviderConfig{
-			Token:      "RANDOM_VALUE",
-			CustomerID: "CUSTOMER",
+			Token:                  "RANDOM_VALUE",
+			CustomerID:             "CUSTOMER",
+			LoadBalancerIdentifier: "LOADBALANCER",
 		}
 
 		logger := klog.NewKlogr()
@@ -37,9 +54,208 @@ var _ = Describe("Initialization", func() {
 		Expect(loadbalancer).ToNot(BeNil())
 		Expect(err).Error().ToNot(HaveOccurred())
 	})
+
+	It("fails without LoadBalancer identifier and auto discovery", func() {
+		config := configuration.ProviderConfig{
+			Token: "RANDOM_VALUE",
+		}
+
+		legacyClient, _ := client.New(client.TokenFromString(config.Token))
+		genericClient, _ := api.NewAPI(api.WithClientOptions(client.TokenFromString(config.Token)))
+
+		_, err := New(&config, klog.NewKlogr(), nil, genericClient, legacyClient, metrics.NewProviderMetrics("anexia", "0.0.0-unit-tests"))
+		Expect(err).To(MatchError(ErrNoLoadBalancers))
+	})
+})
+
+var _ = Describe("Reload", func() {
+	var m *mgr
+
+	BeforeEach(func() {
+		config := configuration.ProviderConfig{
+			Token:                    "RANDOM_VALUE",
+			LoadBalancerIdentifier:   "initial-lb",
+			LoadBalancerBackoffSteps: 30,
+		}
+
+		logger := klog.NewKlogr()
+		legacyClient, _ := client.New(client.TokenFromString(config.Token))
+		genericClient, _ := api.NewAPI(api.WithClientOptions(client.TokenFromString(config.Token)))
+
+		lb, err := New(&config, logger, nil, genericClient, legacyClient, metrics.NewProviderMetrics("anexia", "0.0.0-unit-tests"))
+		Expect(err).NotTo(HaveOccurred())
+
+		m = lb.(*mgr)
+	})
+
+	It("swaps in a valid configuration", func() {
+		err := m.Reload(context.TODO(), &configuration.ProviderConfig{
+			LoadBalancerIdentifier:   "reloaded-lb",
+			LoadBalancerBackoffSteps: 5,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(m.state.Load().loadBalancers).To(ConsistOf("reloaded-lb"))
+		Expect(m.state.Load().backoffSteps).To(Equal(5))
+	})
+
+	It("rejects an unusable configuration and keeps the previous one", func() {
+		err := m.Reload(context.TODO(), &configuration.ProviderConfig{
+			LoadBalancerBackoffSteps: 5,
+		})
+		Expect(err).To(MatchError(ErrNoLoadBalancers))
+
+		Expect(m.state.Load().loadBalancers).To(ConsistOf("initial-lb"))
+		Expect(m.state.Load().backoffSteps).To(Equal(30))
+	})
+})
+
+var _ = Describe("requeueServices", func() {
+	It("continues with the next Service when requeueing one fails, returning all errors", func() {
+		k8sClient := fake.NewSimpleClientset(
+			loadBalancerService("default", "web"),
+			loadBalancerService("default", "db"),
+			loadBalancerService("default", "cache"),
+		)
+		k8sClient.PrependReactor("patch", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if name := action.(k8stesting.PatchAction).GetName(); name != "db" {
+				return true, nil, errors.New("patching " + name + " failed")
+			}
+
+			return false, nil, nil
+		})
+
+		m := mgr{k8s: k8sClient, logger: logr.Discard()}
+
+		err := m.requeueServices(context.TODO())
+		Expect(err).To(MatchError(ContainSubstring("default/web")))
+		Expect(err).To(MatchError(ContainSubstring("default/cache")))
+
+		svc, err := k8sClient.CoreV1().Services("default").Get(context.TODO(), "db", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(svc.Annotations).To(HaveKey(annotationRequeuedAt))
+	})
+})
+
+var _ = Describe("reconcileDNS", func() {
+	It("records a warning event for names not maintained, without failing", func() {
+		genericClient := apimock.NewMockAPI(gomock.NewController(GinkgoT()))
+		genericClient.EXPECT().List(gomock.Any(), &clouddnsv1.Record{ZoneName: "example.com"}, gomock.Any()).
+			DoAndReturn(apimock.ListObjects(clouddnsv1.Record{Identifier: "a-web", Name: "web", Type: "A", RData: "192.0.2.3"}))
+
+		recorder := record.NewFakeRecorder(1)
+		m := mgr{
+			state:    &atomic.Pointer[lbState]{},
+			recorder: recorder,
+		}
+		m.state.Store(&lbState{dns: dns.New(genericClient, "example.com", 300)})
+
+		err := m.reconcileDNS(context.TODO(), "cluster", loadBalancerService("default", "web"), []string{"web.example.com"}, []net.IP{net.ParseIP("192.0.2.1")})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(recorder.Events).To(Receive(And(
+			HavePrefix("Warning DNSNamesNotManaged"),
+			ContainSubstring(dns.ErrNameNotOwned.Error()),
+		)))
+	})
+
+	It("records a warning event for names requested without CloudDNS zone, without failing", func() {
+		recorder := record.NewFakeRecorder(1)
+		m := mgr{
+			state:    &atomic.Pointer[lbState]{},
+			recorder: recorder,
+		}
+		m.state.Store(&lbState{})
+
+		err := m.reconcileDNS(context.TODO(), "cluster", loadBalancerService("default", "web"), []string{"web.example.com"}, []net.IP{net.ParseIP("192.0.2.1")})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(recorder.Events).To(Receive(And(
+			HavePrefix("Warning DNSNamesNotManaged"),
+			ContainSubstring("web.example.com"),
+			ContainSubstring(ErrNoDNSZone.Error()),
+		)))
+	})
 })
 
 func TestLoadBalancer(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "LBaaS operator")
 }
+
+var _ = DescribeTable("diffLoadBalancers",
+	func(prev, next, expectedAdded, expectedRemoved []string) {
+		added, removed := diffLoadBalancers(prev, next)
+		Expect(added).To(Equal(expectedAdded))
+		Expect(removed).To(Equal(expectedRemoved))
+	},
+	Entry("unchanged", []string{"a", "b"}, []string{"b", "a"}, nil, nil),
+	Entry("added", []string{"a"}, []string{"a", "b"}, []string{"b"}, nil),
+	Entry("removed", []string{"a", "b"}, []string{"a"}, nil, []string{"b"}),
+	Entry("replaced", []string{"a"}, []string{"b"}, []string{"b"}, []string{"a"}),
+)
+
+var _ = DescribeTable("annotatedDNSNames",
+	func(annotation string, expected []string) {
+		svc := v1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{AnnotationDNSNames: annotation}}}
+		Expect(annotatedDNSNames(&svc)).To(Equal(expected))
+	},
+	Entry("not set", "", []string{}),
+	Entry("single name", "web.example.com", []string{"web.example.com"}),
+	Entry("multiple names", " web.example.com, ,www.example.com ", []string{"web.example.com", "www.example.com"}),
+)
+
+var _ = DescribeTable("serviceDNSNames",
+	func(proxyPassHostname bool, hostname string, expected []string) {
+		m := mgr{state: &atomic.Pointer[lbState]{}}
+		m.state.Store(&lbState{
+			dns:                  dns.New(nil, "example.com", 300),
+			dnsProxyPassHostname: proxyPassHostname,
+		})
+
+		svc := v1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			AnnotationDNSNames:    "web.example.com",
+			AKEAnnotationHostname: hostname,
+		}}}
+
+		Expect(m.serviceDNSNames(context.TODO(), &svc)).To(Equal(expected))
+	},
+	Entry("proxy pass hostname records disabled", false, "proxy.example.com", []string{"web.example.com"}),
+	Entry("proxy pass hostname inside zone", true, "Proxy.example.com", []string{"web.example.com", "proxy.example.com"}),
+	Entry("proxy pass hostname outside zone", true, "proxy.example.org", []string{"web.example.com"}),
+	Entry("proxy pass hostname not set", true, "", []string{"web.example.com"}),
+)
+
+var _ = DescribeTable("ValidateService",
+	func(annotations map[string]string, ports []v1.ServicePort, expectedErrors ...error) {
+		svc := v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+			Spec:       v1.ServiceSpec{Ports: ports},
+		}
+
+		err := ValidateService(&svc)
+		if len(expectedErrors) == 0 {
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		for _, expected := range expectedErrors {
+			Expect(err).To(MatchError(expected))
+		}
+	},
+	Entry("valid Service", map[string]string{
+		"lbaas.anx.io/external-ip-families": "IPv4,IPv6",
+		AKEAnnotationHostname:               "Web.Example.com",
+		AnnotationDNSNames:                  "web.example.com., www.example.com",
+	}, []v1.ServicePort{{Name: "http", Port: 80}, {Name: "https", Port: 443}}),
+	Entry("invalid IP family", map[string]string{
+		"lbaas.anx.io/external-ip-families": "ipv4",
+	}, nil, address.ErrInvalidIPFamiliesAnnotation),
+	Entry("duplicate port names", nil,
+		[]v1.ServicePort{{Name: "http", Port: 80}, {Name: "http", Port: 8080}},
+		ErrPortNameNotUnique,
+	),
+	Entry("invalid hostnames", map[string]string{
+		AKEAnnotationHostname: "web_example.com",
+		AnnotationDNSNames:    "-web.example.com",
+	}, nil, ErrInvalidHostname),
+)
@@ -0,0 +1,213 @@
+// Package provisioning creates LBaaS LoadBalancers for a cluster when auto discovery finds none, and removes them
+// again when the cluster is decommissioned.
+//
+// Only the LBaaS LoadBalancer resources are created, the LBaaS machines they refer to are provided by Anexia.
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/go-logr/logr"
+	"go.anx.io/go-anxcloud/pkg/api"
+	"go.anx.io/go-anxcloud/pkg/api/types"
+	corev1 "go.anx.io/go-anxcloud/pkg/apis/core/v1"
+	lbaasv1 "go.anx.io/go-anxcloud/pkg/apis/lbaas/v1"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/configuration"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/loadbalancer/address"
+)
+
+// ProvisionedTag returns the tag marking LoadBalancers created for the given auto discovery tag, making sure only
+// those are removed by Teardown.
+func ProvisionedTag(discoveryTag string) string {
+	return discoveryTag + "-provisioned"
+}
+
+// Provision makes sure the configured LBaaS LoadBalancers exist, tagged as provisioned and with the given auto
+// discovery tag, and tags the configured prefix for auto discovery. LoadBalancers are matched by name with the ones
+// tagged as provisioned before and only missing ones are created, completing a partially provisioned set on the
+// next call. The identifiers of all configured LoadBalancers are returned.
+func Provision(ctx context.Context, apiClient api.API, discoveryTag, clusterName string, config configuration.LoadBalancerProvisioning) ([]string, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	if config.PrefixIdentifier != "" {
+		if err := ensureTagged(ctx, apiClient, config.PrefixIdentifier, address.PrefixDiscoveryTag(clusterName)); err != nil {
+			return nil, fmt.Errorf("error tagging prefix %q for auto discovery: %w", config.PrefixIdentifier, err)
+		}
+	}
+
+	provisioned, err := provisionedByName(ctx, apiClient, discoveryTag)
+	if err != nil {
+		return nil, err
+	}
+
+	discovered, err := taggedResources(ctx, apiClient, discoveryTag)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]string, 0, len(config.LoadBalancers))
+
+	for i, spec := range config.LoadBalancers {
+		name := loadBalancerName(clusterName, i, spec)
+
+		identifier, ok := provisioned[name]
+		if !ok {
+			if identifier, err = create(ctx, apiClient, discoveryTag, name, spec); err != nil {
+				return ret, err
+			}
+
+			logger.Info("Provisioned LoadBalancer", "name", name, "identifier", identifier, "address", spec.Address)
+		} else if !slices.Contains(discovered, identifier) {
+			// tagging for discovery failed when it was created
+			if err := tag(ctx, apiClient, identifier, discoveryTag); err != nil {
+				return ret, fmt.Errorf("error tagging LoadBalancer %q for auto discovery: %w", name, err)
+			}
+		}
+
+		ret = append(ret, identifier)
+	}
+
+	return ret, nil
+}
+
+// create creates a LoadBalancer with the given name and tags it. When tagging it as provisioned fails, it is
+// destroyed again, since it would neither be completed by Provision nor destroyed by Teardown.
+func create(ctx context.Context, apiClient api.API, discoveryTag, name string, spec configuration.ProvisionedLoadBalancer) (string, error) {
+	lb := lbaasv1.LoadBalancer{
+		Name:      name,
+		IpAddress: spec.Address,
+	}
+
+	if err := apiClient.Create(ctx, &lb); err != nil {
+		return "", fmt.Errorf("error creating LoadBalancer %q: %w", name, err)
+	}
+
+	// tagged as provisioned first, a LoadBalancer only tagged for discovery would never be torn down
+	if err := tag(ctx, apiClient, lb.Identifier, ProvisionedTag(discoveryTag)); err != nil {
+		err = fmt.Errorf("error tagging LoadBalancer %q as provisioned: %w", name, err)
+
+		if destroyErr := apiClient.Destroy(ctx, &lb); destroyErr != nil {
+			return "", errors.Join(err, fmt.Errorf("error destroying untagged LoadBalancer %q (%s), remove it manually: %w", name, lb.Identifier, destroyErr))
+		}
+
+		return "", err
+	}
+
+	if err := tag(ctx, apiClient, lb.Identifier, discoveryTag); err != nil {
+		return "", fmt.Errorf("error tagging LoadBalancer %q for auto discovery: %w", name, err)
+	}
+
+	return lb.Identifier, nil
+}
+
+// provisionedByName returns the identifiers of the LoadBalancers created by Provision for the given auto discovery
+// tag, by their name.
+func provisionedByName(ctx context.Context, apiClient api.API, discoveryTag string) (map[string]string, error) {
+	identifiers, err := Provisioned(ctx, apiClient, discoveryTag)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string]string, len(identifiers))
+
+	for _, identifier := range identifiers {
+		lb := lbaasv1.LoadBalancer{Identifier: identifier}
+		if err := apiClient.Get(ctx, &lb); err != nil {
+			return nil, fmt.Errorf("error retrieving provisioned LoadBalancer %q: %w", identifier, err)
+		}
+
+		ret[lb.Name] = identifier
+	}
+
+	return ret, nil
+}
+
+// Provisioned returns the identifiers of the resources tagged as created by Provision for the given auto discovery tag.
+func Provisioned(ctx context.Context, apiClient api.API, discoveryTag string) ([]string, error) {
+	return taggedResources(ctx, apiClient, ProvisionedTag(discoveryTag))
+}
+
+// Teardown destroys all LBaaS LoadBalancers created by Provision for the given auto discovery tag, returning the
+// identifiers of the destroyed ones. LoadBalancers not created by Provision and the tagged prefix are left alone.
+func Teardown(ctx context.Context, apiClient api.API, discoveryTag string) ([]string, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	identifiers, err := Provisioned(ctx, apiClient, discoveryTag)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]string, 0, len(identifiers))
+
+	for _, identifier := range identifiers {
+		lb := lbaasv1.LoadBalancer{Identifier: identifier}
+		if err := apiClient.Get(ctx, &lb); err != nil {
+			logger.Error(err, "Error retrieving LoadBalancer, maybe something else is tagged? Ignoring this one",
+				"identifier", identifier,
+			)
+			continue
+		}
+
+		if err := apiClient.Destroy(ctx, &lb); err != nil {
+			return ret, fmt.Errorf("error destroying LoadBalancer %q: %w", lb.Name, err)
+		}
+
+		logger.Info("Destroyed provisioned LoadBalancer", "name", lb.Name, "identifier", identifier)
+		ret = append(ret, identifier)
+	}
+
+	return ret, nil
+}
+
+// loadBalancerName returns the configured name of the LoadBalancer or "$clusterName-$index" when none is given.
+func loadBalancerName(clusterName string, index int, spec configuration.ProvisionedLoadBalancer) string {
+	if spec.Name != "" {
+		return spec.Name
+	}
+
+	return fmt.Sprintf("%s-%d", clusterName, index)
+}
+
+func tag(ctx context.Context, apiClient api.API, identifier, tag string) error {
+	return apiClient.Create(ctx, &corev1.ResourceWithTag{Identifier: identifier, Tag: tag})
+}
+
+// ensureTagged tags the resource with the given identifier, unless it is tagged already.
+func ensureTagged(ctx context.Context, apiClient api.API, identifier, t string) error {
+	tagged, err := taggedResources(ctx, apiClient, t)
+	if err != nil {
+		return err
+	}
+
+	if slices.Contains(tagged, identifier) {
+		return nil
+	}
+
+	return tag(ctx, apiClient, identifier, t)
+}
+
+func taggedResources(ctx context.Context, apiClient api.API, tag string) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var oc types.ObjectChannel
+	if err := apiClient.List(ctx, &corev1.Resource{Tags: []string{tag}}, api.ObjectChannel(&oc)); err != nil {
+		return nil, fmt.Errorf("error listing resources tagged %q: %w", tag, err)
+	}
+
+	ret := make([]string, 0)
+	for retriever := range oc {
+		var res corev1.Resource
+		if err := retriever(&res); err != nil {
+			return nil, fmt.Errorf("error retrieving resource: %w", err)
+		}
+
+		ret = append(ret, res.Identifier)
+	}
+
+	return ret, nil
+}
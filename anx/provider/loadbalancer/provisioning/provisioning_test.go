@@ -0,0 +1,152 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/configuration"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/loadbalancer/address"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/test/apimock"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.anx.io/go-anxcloud/pkg/api/mock"
+	"go.anx.io/go-anxcloud/pkg/api/types"
+	corev1 "go.anx.io/go-anxcloud/pkg/apis/core/v1"
+	lbaasv1 "go.anx.io/go-anxcloud/pkg/apis/lbaas/v1"
+)
+
+func TestProvisioning(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "LoadBalancer provisioning")
+}
+
+var _ = DescribeTable("loadBalancerName",
+	func(spec configuration.ProvisionedLoadBalancer, expected string) {
+		Expect(loadBalancerName("cluster", 1, spec)).To(Equal(expected))
+	},
+	Entry("configured name", configuration.ProvisionedLoadBalancer{Name: "ingress"}, "ingress"),
+	Entry("default name", configuration.ProvisionedLoadBalancer{}, "cluster-1"),
+)
+
+var _ = Describe("Teardown", func() {
+	const discoveryTag = "kubernetes-lb-cluster"
+
+	var apiClient mock.API
+	var provisioned, discovered string
+
+	BeforeEach(func() {
+		apiClient = mock.NewMockAPI()
+
+		provisioned = apiClient.FakeExisting(&lbaasv1.LoadBalancer{Name: "provisioned"}, discoveryTag, ProvisionedTag(discoveryTag))
+		discovered = apiClient.FakeExisting(&lbaasv1.LoadBalancer{Name: "discovered"}, discoveryTag)
+	})
+
+	It("destroys only provisioned LoadBalancers", func() {
+		identifiers, err := Teardown(context.TODO(), apiClient, discoveryTag)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(identifiers).To(ConsistOf(provisioned))
+		Expect(apiClient.Inspect(provisioned).Existing()).To(BeFalse())
+		Expect(apiClient.Inspect(discovered).Existing()).To(BeTrue())
+	})
+})
+
+var _ = Describe("Provision", func() {
+	const discoveryTag = "kubernetes-lb-cluster"
+
+	var apiClient *apimock.MockAPI
+
+	config := configuration.LoadBalancerProvisioning{
+		LoadBalancers: []configuration.ProvisionedLoadBalancer{
+			{Address: "198.51.100.10"},
+			{Address: "198.51.100.11"},
+		},
+	}
+
+	BeforeEach(func() {
+		apiClient = apimock.NewMockAPI(gomock.NewController(GinkgoT()))
+	})
+
+	expectTagged := func(tag string, identifiers ...string) {
+		apiClient.EXPECT().List(gomock.Any(), &corev1.Resource{Tags: []string{tag}}, gomock.Any()).
+			DoAndReturn(apimock.ListResources(identifiers...))
+	}
+
+	expectCreated := func(name, address, identifier string) {
+		apiClient.EXPECT().Create(gomock.Any(), &lbaasv1.LoadBalancer{Name: name, IpAddress: address}).
+			DoAndReturn(func(_ context.Context, o types.Object, _ ...types.CreateOption) error {
+				o.(*lbaasv1.LoadBalancer).Identifier = identifier
+				return nil
+			})
+	}
+
+	expectTag := func(identifier, tag string, err error) {
+		apiClient.EXPECT().Create(gomock.Any(), &corev1.ResourceWithTag{Identifier: identifier, Tag: tag}).Return(err)
+	}
+
+	It("creates and tags all LoadBalancers when none were provisioned", func() {
+		expectTagged(ProvisionedTag(discoveryTag))
+		expectTagged(discoveryTag)
+
+		expectCreated("cluster-0", "198.51.100.10", "lb-0")
+		expectTag("lb-0", ProvisionedTag(discoveryTag), nil)
+		expectTag("lb-0", discoveryTag, nil)
+
+		expectCreated("cluster-1", "198.51.100.11", "lb-1")
+		expectTag("lb-1", ProvisionedTag(discoveryTag), nil)
+		expectTag("lb-1", discoveryTag, nil)
+
+		identifiers, err := Provision(context.TODO(), apiClient, discoveryTag, "cluster", config)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(identifiers).To(Equal([]string{"lb-0", "lb-1"}))
+	})
+
+	It("completes a partially provisioned set", func() {
+		// the first LoadBalancer got tagged as provisioned, but tagging it for discovery failed
+		expectTagged(ProvisionedTag(discoveryTag), "lb-0")
+		apiClient.EXPECT().Get(gomock.Any(), &lbaasv1.LoadBalancer{Identifier: "lb-0"}).
+			DoAndReturn(func(_ context.Context, o types.IdentifiedObject, _ ...types.GetOption) error {
+				o.(*lbaasv1.LoadBalancer).Name = "cluster-0"
+				return nil
+			})
+		expectTagged(discoveryTag)
+
+		expectTag("lb-0", discoveryTag, nil)
+
+		expectCreated("cluster-1", "198.51.100.11", "lb-1")
+		expectTag("lb-1", ProvisionedTag(discoveryTag), nil)
+		expectTag("lb-1", discoveryTag, nil)
+
+		identifiers, err := Provision(context.TODO(), apiClient, discoveryTag, "cluster", config)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(identifiers).To(Equal([]string{"lb-0", "lb-1"}))
+	})
+
+	It("destroys a created LoadBalancer it cannot tag as provisioned", func() {
+		expectTagged(ProvisionedTag(discoveryTag))
+		expectTagged(discoveryTag)
+
+		expectCreated("cluster-0", "198.51.100.10", "lb-0")
+		expectTag("lb-0", ProvisionedTag(discoveryTag), errors.New("engine unavailable"))
+		apiClient.EXPECT().Destroy(gomock.Any(), &lbaasv1.LoadBalancer{Identifier: "lb-0", Name: "cluster-0", IpAddress: "198.51.100.10"}).
+			Return(nil)
+
+		identifiers, err := Provision(context.TODO(), apiClient, discoveryTag, "cluster", config)
+		Expect(err).To(MatchError(ContainSubstring("engine unavailable")))
+		Expect(identifiers).To(BeEmpty())
+	})
+
+	It("tags the prefix only when not tagged already", func() {
+		prefixConfig := configuration.LoadBalancerProvisioning{PrefixIdentifier: "prefix"}
+
+		expectTagged(address.PrefixDiscoveryTag("cluster"), "prefix")
+		expectTagged(ProvisionedTag(discoveryTag))
+		expectTagged(discoveryTag)
+
+		identifiers, err := Provision(context.TODO(), apiClient, discoveryTag, "cluster", prefixConfig)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(identifiers).To(BeEmpty())
+	})
+})
@@ -1,11 +1,21 @@
 package reconciliation
 
 import (
+	"errors"
+	"maps"
+	"slices"
 	"sync"
 
 	"go.anx.io/go-anxcloud/pkg/api/types"
 )
 
+// ErrSecondaryOutOfSync is reported for a secondary Reconciliation whose status differs from the primary one.
+var ErrSecondaryOutOfSync = errors.New("status differs from primary")
+
+// SecondaryStatusFunc is called by Status of a MultiReconciliation with a primary Reconciliation, once for
+// every secondary Reconciliation. err is nil when the secondary has the same status as the primary.
+type SecondaryStatusFunc func(secondary Reconciliation, err error)
+
 // MultiReconciliation is a collection of Reconcilation to do concurrently.
 type MultiReconciliation interface {
 	// Add another Reconcilation to the collection of reconciliations to do.
@@ -16,6 +26,10 @@ type MultiReconciliation interface {
 
 type multirecon struct {
 	recons []Reconciliation
+
+	// when set, the first Reconciliation in recons is the primary one and the source of truth for Status
+	withPrimary     bool
+	secondaryStatus SecondaryStatusFunc
 }
 
 // Multi creates a new MultiReconcilation from the given Reconcilation instances. Instead of
@@ -38,6 +52,21 @@ func Multi(recon ...Reconciliation) MultiReconciliation {
 	}
 }
 
+// MultiWithPrimary creates a new MultiReconciliation like Multi, but with the given primary Reconciliation being
+// the source of truth for Status. Reconciliations added later via Add() are secondaries, they are reconciled
+// like the primary, but their status is only compared to the one of the primary and reported via the given
+// SecondaryStatusFunc.
+//
+// This is used for statically configured LBaaS LoadBalancers, where one is the primary and the others are kept
+// in sync with it.
+func MultiWithPrimary(primary Reconciliation, secondaryStatus SecondaryStatusFunc) MultiReconciliation {
+	return &multirecon{
+		recons:          []Reconciliation{primary},
+		withPrimary:     true,
+		secondaryStatus: secondaryStatus,
+	}
+}
+
 func (mr *multirecon) Add(recon Reconciliation) {
 	if mr.recons == nil {
 		mr.recons = make([]Reconciliation, 0, 1)
@@ -115,22 +144,19 @@ func (mr *multirecon) Reconcile() error {
 }
 
 func (mr *multirecon) Status() (map[string][]uint16, error) {
-	type singleResult struct {
-		status map[string][]uint16
-		err    error
-	}
-
 	wg := sync.WaitGroup{}
 	wg.Add(len(mr.recons))
 
-	results := make(chan singleResult, len(mr.recons))
+	results := make(chan statusResult, len(mr.recons))
 	for i := range mr.recons {
 		recon := mr.recons[i]
 		go func() {
 			defer wg.Done()
 			status, err := recon.Status()
 
-			results <- singleResult{
+			results <- statusResult{
+				index:  i,
+				recon:  recon,
 				status: status,
 				err:    err,
 			}
@@ -140,6 +166,10 @@ func (mr *multirecon) Status() (map[string][]uint16, error) {
 	wg.Wait()
 	close(results)
 
+	if mr.withPrimary {
+		return mr.primaryStatus(results)
+	}
+
 	status := make([]map[string][]uint16, 0, len(mr.recons))
 
 	for result := range results {
@@ -154,6 +184,55 @@ func (mr *multirecon) Status() (map[string][]uint16, error) {
 
 }
 
+type statusResult struct {
+	index  int
+	recon  Reconciliation
+	status map[string][]uint16
+	err    error
+}
+
+// primaryStatus returns the status of the primary Reconciliation, reporting the status of every secondary
+// compared to it.
+func (mr *multirecon) primaryStatus(results <-chan statusResult) (map[string][]uint16, error) {
+	secondaries := make([]statusResult, 0, len(mr.recons)-1)
+
+	var primary statusResult
+	for result := range results {
+		if result.index == 0 {
+			primary = result
+		} else {
+			secondaries = append(secondaries, result)
+		}
+	}
+
+	if primary.err != nil {
+		return nil, primary.err
+	}
+
+	for _, secondary := range secondaries {
+		err := secondary.err
+		if err == nil && !statusEqual(primary.status, secondary.status) {
+			err = ErrSecondaryOutOfSync
+		}
+
+		if mr.secondaryStatus != nil {
+			mr.secondaryStatus(secondary.recon, err)
+		}
+	}
+
+	return primary.status, nil
+}
+
+// statusEqual checks if both given status have the same addresses with the same ports, ignoring order.
+func statusEqual(a, b map[string][]uint16) bool {
+	return maps.EqualFunc(a, b, func(portsA, portsB []uint16) bool {
+		portsA, portsB = slices.Clone(portsA), slices.Clone(portsB)
+		slices.Sort(portsA)
+		slices.Sort(portsB)
+		return slices.Equal(portsA, portsB)
+	})
+}
+
 func mergeReconStatus(status []map[string][]uint16) map[string][]uint16 {
 	addressPortReturnedCount := make(map[string]map[uint16]int)
 
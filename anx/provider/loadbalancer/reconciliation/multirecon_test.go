@@ -57,6 +57,50 @@ var _ = Describe("Multi", func() {
 		})
 	})
 
+	Context("Status with primary", func() {
+		var reportedErrors []error
+
+		primary := testRecon{
+			status: map[string][]uint16{
+				"8.8.8.8": {80, 443},
+			},
+		}
+
+		BeforeEach(func() {
+			reportedErrors = make([]error, 0)
+
+			recon = MultiWithPrimary(primary, func(_ Reconciliation, err error) {
+				reportedErrors = append(reportedErrors, err)
+			})
+		})
+
+		It("returns the status of the primary and reports secondaries in sync", func() {
+			recon.(MultiReconciliation).Add(testRecon{
+				status: map[string][]uint16{
+					"8.8.8.8": {443, 80},
+				},
+			})
+
+			status, err := recon.Status()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(Equal(primary.status))
+			Expect(reportedErrors).To(ConsistOf(BeNil()))
+		})
+
+		It("returns the status of the primary and reports secondaries out of sync", func() {
+			recon.(MultiReconciliation).Add(testRecon{
+				status: map[string][]uint16{
+					"8.8.8.8": {80},
+				},
+			})
+
+			status, err := recon.Status()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(Equal(primary.status))
+			Expect(reportedErrors).To(ConsistOf(MatchError(ErrSecondaryOutOfSync)))
+		})
+	})
+
 	Context("ReconcileCheck aggregation", func() {
 		Context("toCreate", func() {
 			BeforeEach(func() {
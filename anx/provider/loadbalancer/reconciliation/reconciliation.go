@@ -10,7 +10,9 @@ import (
 	"time"
 
 	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/metrics"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/tracing"
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
 
 	"k8s.io/apimachinery/pkg/util/wait"
 
@@ -198,15 +200,22 @@ func (r *reconciliation) ReconcileCheck() ([]types.Object, []types.Object, error
 	retToDestroy := []types.Object{}
 	retToCreate := []types.Object{}
 
-	steps := []func() ([]types.Object, []types.Object, error){
-		r.reconcileBackends,
-		r.reconcileFrontends,
-		r.reconcileBinds,
-		r.reconcileServers,
+	steps := []struct {
+		name string
+		run  func() ([]types.Object, []types.Object, error)
+	}{
+		{"reconcileBackends", r.reconcileBackends},
+		{"reconcileFrontends", r.reconcileFrontends},
+		{"reconcileBinds", r.reconcileBinds},
+		{"reconcileServers", r.reconcileServers},
 	}
 
 	for _, step := range steps {
-		toCreate, toDestroy, err := step()
+		_, span := tracing.Start(r.ctx, step.name, r.spanAttributes()...)
+		toCreate, toDestroy, err := step.run()
+
+		span.SetAttributes(attribute.Int("lbaas.to_create", len(toCreate)), attribute.Int("lbaas.to_destroy", len(toDestroy)))
+		tracing.End(span, err)
 
 		if err != nil {
 			return nil, nil, err
@@ -223,11 +232,14 @@ func (r *reconciliation) ReconcileCheck() ([]types.Object, []types.Object, error
 // configuration. The LBaaS API serializes Update operations with state ID 0
 // (Updating), so failed resources are recovered without deleting their Engine
 // object while leaving the corresponding HAProxy configuration behind.
-func (r *reconciliation) updateFailedResources() error {
+func (r *reconciliation) updateFailedResources() (err error) {
 	r.logger.Info("Resetting failed LBaaS resources to Updating", "objects", mustStringifyObjects(r.existingFailed))
 
+	ctx, span := tracing.Start(r.ctx, "updateFailedResources", r.spanAttributes()...)
+	defer func() { tracing.End(span, err) }()
+
 	for _, obj := range r.existingFailed {
-		if err := r.api.Update(r.ctx, obj); err != nil {
+		if err := r.api.Update(ctx, obj); err != nil {
 			return fmt.Errorf("error resetting failed LBaaS resource to Updating: %w", err)
 		}
 	}
@@ -266,9 +278,13 @@ func (r *reconciliation) Reconcile() error {
 		// after there is nothing left to destroy.
 
 		if len(toDestroy) > 0 {
-			r.metrics.ReconciliationPendingResources.WithLabelValues("lbaas", "destroy").Add(float64(len(toDestroy)))
+			r.metrics.ReconciliationPendingResources.WithLabelValues(r.metrics.ServiceLabelValues("destroy")...).Add(float64(len(toDestroy)))
 			r.logger.V(1).Info("destroying resources", "objects", mustStringifyObjects(toDestroy))
 
+			ctx, span := tracing.Start(r.ctx, "destroyResources", append(r.spanAttributes(),
+				attribute.Int("lbaas.to_destroy", len(toDestroy)),
+			)...)
+
 			allowRetry := true
 
 		outer:
@@ -278,7 +294,7 @@ func (r *reconciliation) Reconcile() error {
 				newToDestroy := make([]types.Object, 0, len(toDestroy))
 
 				for _, obj := range toDestroy {
-					err := r.api.Destroy(r.ctx, obj)
+					err := r.api.Destroy(ctx, obj)
 					err = api.IgnoreNotFound(err) // We deliberately want to ignore not found errors, as they indicate success.
 
 					newToDestroy, err = handleDestroyError(err, newToDestroy, obj, r)
@@ -291,7 +307,7 @@ func (r *reconciliation) Reconcile() error {
 					allowRetry = true
 
 					r.metrics.ReconciliationDeletedTotal.WithLabelValues("lbaas").Inc()
-					r.metrics.ReconciliationPendingResources.WithLabelValues("lbaas", "destroy").Dec()
+					r.metrics.ReconciliationPendingResources.WithLabelValues(r.metrics.ServiceLabelValues("destroy")...).Dec()
 				}
 
 				toDestroy = newToDestroy
@@ -302,38 +318,26 @@ func (r *reconciliation) Reconcile() error {
 					"objects", mustStringifyObjects(toDestroy),
 				)
 
-				r.metrics.ReconciliationDeleteErrorsTotal.WithLabelValues("lbaas").Inc()
+				r.metrics.ReconciliationDeleteErrorsTotal.WithLabelValues(r.metrics.ServiceLabelValues()...).Inc()
 
+				tracing.End(span, ErrResourcesNotDestroyable)
 				return ErrResourcesNotDestroyable
 			}
+
+			tracing.End(span, nil)
 		} else if len(toCreate) > 0 {
-			r.metrics.ReconciliationPendingResources.WithLabelValues("lbaas", "create").Add(float64(len(toCreate)))
+			r.metrics.ReconciliationPendingResources.WithLabelValues(r.metrics.ServiceLabelValues("create")...).Add(float64(len(toCreate)))
 			r.logger.V(1).Info("creating resources", "count", len(toCreate))
 
-			for _, obj := range toCreate {
-				if err := r.api.Create(r.ctx, obj); err != nil {
-					// Ensure decrementing pending resources before returning to prevent leakage
-					r.metrics.ReconciliationPendingResources.WithLabelValues("lbaas", "create").Dec()
-					r.metrics.ReconciliationCreateErrorsTotal.WithLabelValues("lbaas").Inc()
-					return fmt.Errorf("error creating LBaaS resource: %w", err)
-				}
-
-				if err := r.tagResource(obj); err != nil {
-					// Ensure decrementing pending resources before returning to prevent leakage
-					r.metrics.ReconciliationPendingResources.WithLabelValues("lbaas", "create").Dec()
-					r.metrics.ReconciliationCreateErrorsTotal.WithLabelValues("lbaas").Inc()
-					return fmt.Errorf("error tagging LBaaS resource: %w", err)
-				}
-
-				r.metrics.ReconciliationPendingResources.WithLabelValues("lbaas", "create").Dec()
-				r.metrics.ReconciliationCreatedTotal.WithLabelValues("lbaas").Inc()
+			if err := r.createResources(toCreate); err != nil {
+				return err
 			}
 			r.logger.Info("waiting for created resources to become ready", "objects", mustStringifyObjects(toCreate))
 
 			startTimeCreate := time.Now()
 			err := r.waitForResources(toCreate)
 			if err != nil && !errors.Is(err, ErrLBaaSResourceFailed) {
-				r.metrics.ReconciliationCreateErrorsTotal.WithLabelValues("lbaas").Inc()
+				r.metrics.ReconciliationCreateErrorsTotal.WithLabelValues(r.metrics.ServiceLabelValues()...).Inc()
 				return err
 			}
 
@@ -342,7 +346,36 @@ func (r *reconciliation) Reconcile() error {
 			completed = true
 		}
 
-		r.metrics.ReconciliationTotalDuration.WithLabelValues("lbaas").Observe(float64(time.Since(startTimeTotal).Seconds()))
+		r.metrics.ReconciliationTotalDuration.WithLabelValues(r.metrics.ServiceLabelValues()...).Observe(float64(time.Since(startTimeTotal).Seconds()))
+	}
+
+	return nil
+}
+
+// createResources creates and tags the given LBaaS resources.
+func (r *reconciliation) createResources(toCreate []types.Object) (err error) {
+	ctx, span := tracing.Start(r.ctx, "createResources", append(r.spanAttributes(),
+		attribute.Int("lbaas.to_create", len(toCreate)),
+	)...)
+	defer func() { tracing.End(span, err) }()
+
+	for _, obj := range toCreate {
+		if err := r.api.Create(ctx, obj); err != nil {
+			// Ensure decrementing pending resources before returning to prevent leakage
+			r.metrics.ReconciliationPendingResources.WithLabelValues(r.metrics.ServiceLabelValues("create")...).Dec()
+			r.metrics.ReconciliationCreateErrorsTotal.WithLabelValues(r.metrics.ServiceLabelValues()...).Inc()
+			return fmt.Errorf("error creating LBaaS resource: %w", err)
+		}
+
+		if err := r.tagResource(ctx, obj); err != nil {
+			// Ensure decrementing pending resources before returning to prevent leakage
+			r.metrics.ReconciliationPendingResources.WithLabelValues(r.metrics.ServiceLabelValues("create")...).Dec()
+			r.metrics.ReconciliationCreateErrorsTotal.WithLabelValues(r.metrics.ServiceLabelValues()...).Inc()
+			return fmt.Errorf("error tagging LBaaS resource: %w", err)
+		}
+
+		r.metrics.ReconciliationPendingResources.WithLabelValues(r.metrics.ServiceLabelValues("create")...).Dec()
+		r.metrics.ReconciliationCreatedTotal.WithLabelValues("lbaas").Inc()
 	}
 
 	return nil
@@ -368,7 +401,7 @@ func handleDestroyError(err error, newToDestroy []types.Object, obj types.Object
 
 var _engsup5902_mutex = sync.Mutex{}
 
-func (r *reconciliation) tagResource(o types.Object) error {
+func (r *reconciliation) tagResource(ctx context.Context, o types.Object) error {
 	_engsup5902_mutex.Lock()
 	defer _engsup5902_mutex.Unlock()
 
@@ -380,7 +413,7 @@ func (r *reconciliation) tagResource(o types.Object) error {
 			Tag:        tag,
 		}
 
-		if err := r.api.Create(r.ctx, &rt); err != nil {
+		if err := r.api.Create(ctx, &rt); err != nil {
 			return err
 		}
 	}
@@ -411,6 +444,7 @@ func (r *reconciliation) Status() (map[string][]uint16, error) {
 func (r *reconciliation) waitForResources(toCreate []types.Object) error {
 	// we want to retrieve every Object in this loop at least once, to deal with "defaults-to-success" and similar things.
 	firstPass := true
+	iteration := 0
 
 	return wait.ExponentialBackoff(
 		wait.Backoff{
@@ -421,9 +455,21 @@ func (r *reconciliation) waitForResources(toCreate []types.Object) error {
 			Cap:      5 * time.Minute,
 		},
 		func() (done bool, err error) {
+			iteration++
+
+			ctx, span := tracing.Start(r.ctx, "waitForResources", append(r.spanAttributes(),
+				attribute.Int("lbaas.iteration", iteration),
+				attribute.Int("lbaas.objects", len(toCreate)),
+			)...)
+
 			notReady := make([]types.Object, 0, len(toCreate))
 			failed := make([]types.Object, 0, len(toCreate))
 
+			defer func() {
+				span.SetAttributes(attribute.Int("lbaas.not_ready", len(notReady)), attribute.Int("lbaas.failed", len(failed)))
+				tracing.End(span, err)
+			}()
+
 			for _, obj := range toCreate {
 				state, ok := obj.(gs.StateRetriever)
 				if !ok {
@@ -436,7 +482,7 @@ func (r *reconciliation) waitForResources(toCreate []types.Object) error {
 					continue
 				}
 
-				err := r.api.Get(r.ctx, obj)
+				err := r.api.Get(ctx, obj)
 				if err != nil {
 					r.logger.Error(err, "Error retrieving current state of Object, assuming it's failed", "object", mustStringifyObject(obj))
 					failed = append(failed, obj)
@@ -478,11 +524,16 @@ func (r *reconciliation) retrieveState() error {
 	r.existingProgressing = make([]types.Object, 0)
 	r.existingUpdating = make([]types.Object, 0)
 
-	if err := r.retrieveResources(); err != nil {
-		return err
-	}
+	ctx, span := tracing.Start(r.ctx, "retrieveState", r.spanAttributes()...)
+	err := r.retrieveResources(ctx)
+	tracing.End(span, err)
 
-	return nil
+	return err
+}
+
+// spanAttributes returns the attributes identifying this reconciliation on spans.
+func (r *reconciliation) spanAttributes() []attribute.KeyValue {
+	return []attribute.KeyValue{attribute.String("lbaas.loadbalancer", r.lb.Identifier)}
 }
 
 func (r *reconciliation) sortObjectIntoStateArray(o types.Object) {
@@ -517,8 +568,8 @@ func isResourceUpdating(o types.Object) bool {
 	}
 }
 
-func (r *reconciliation) retrieveResources() error {
-	ctx, cancel := context.WithCancel(r.ctx)
+func (r *reconciliation) retrieveResources(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	var oc types.ObjectChannel
@@ -648,8 +648,8 @@ var _ = Describe("reconcile", func() {
 				err = testutil.GatherAndCompare(kubeRegistry, strings.NewReader(`
 				# HELP cloud_provider_anexia_reconcile_resources_pending [ALPHA] Gauge of pending creation or deletion operations of resources
 				# TYPE cloud_provider_anexia_reconcile_resources_pending gauge
-				cloud_provider_anexia_reconcile_resources_pending{operation="create",service="lbaas"} 0
-				cloud_provider_anexia_reconcile_resources_pending{operation="destroy",service="lbaas"} 0
+				cloud_provider_anexia_reconcile_resources_pending{name="",namespace="",operation="create",service="lbaas"} 0
+				cloud_provider_anexia_reconcile_resources_pending{name="",namespace="",operation="destroy",service="lbaas"} 0
 				`), "cloud_provider_anexia_reconcile_resources_pending")
 				Expect(err).ToNot(HaveOccurred())
 			})
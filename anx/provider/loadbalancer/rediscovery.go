@@ -0,0 +1,219 @@
+package loadbalancer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/loadbalancer/discovery"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/loadbalancer/reconciliation"
+)
+
+// rediscoveryDisabledRecheckInterval is used to check again if rediscovery got enabled by a configuration reload.
+const rediscoveryDisabledRecheckInterval = 1 * time.Minute
+
+// Discoverer is implemented by LoadBalancer managers able to discover LBaaS LoadBalancers at runtime.
+type Discoverer interface {
+	// RunDiscovery discovers LBaaS LoadBalancers in the configured interval until ctx is done.
+	RunDiscovery(ctx context.Context)
+}
+
+// RunDiscovery discovers the tagged LBaaS LoadBalancers again in the configured interval, until ctx is done.
+// Newly found LoadBalancers are added to the set of LoadBalancers to configure and all LoadBalancer Services are
+// requeued to have them reconciled onto the new LoadBalancers. Resources on LoadBalancers no longer tagged are
+// drained, retrying on every rediscovery until draining succeeded.
+//
+// Nothing is done while auto discovery or rediscovery are disabled.
+func (m *mgr) RunDiscovery(ctx context.Context) {
+	for {
+		interval := m.state.Load().discoveryInterval
+		if interval <= 0 {
+			interval = rediscoveryDisabledRecheckInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+
+		if err := m.rediscover(ctx); err != nil {
+			m.logger.Error(err, "Error rediscovering LoadBalancers, keeping the current set")
+		}
+	}
+}
+
+func (m *mgr) rediscover(ctx context.Context) error {
+	state := m.state.Load()
+	if state.discoveryTag == "" || state.discoveryInterval <= 0 {
+		return nil
+	}
+
+	lbs, err := discovery.DiscoverLoadBalancers(ctx, m.api, state.discoveryTag)
+	if err != nil {
+		return err
+	}
+
+	// we don't want to drain everything because of a single empty discovery result
+	if len(lbs) == 0 {
+		return ErrNoLoadBalancers
+	}
+
+	added, removed := m.swapDiscoveredLoadBalancers(state.discoveryTag, lbs)
+	if len(added) > 0 || len(removed) > 0 {
+		m.logger.Info("Set of discovered LoadBalancers changed", "added", added, "removed", removed)
+	}
+
+	for _, lb := range m.state.Load().pendingDrains {
+		if err := m.drainLoadBalancer(ctx, lb); err != nil {
+			m.logger.Error(err, "Error draining LoadBalancer no longer tagged, retrying on next rediscovery", "loadbalancer", lb)
+			continue
+		}
+
+		m.markDrained(lb)
+	}
+
+	if len(added) > 0 {
+		return m.requeueServices(ctx)
+	}
+
+	return nil
+}
+
+// swapDiscoveredLoadBalancers stores the given LoadBalancers, discovered with the given tag, as the ones to configure
+// and returns the ones added and removed. Removed LoadBalancers are recorded as to be drained. Nothing is changed when the configuration was reloaded with a different
+// discovery tag in the meantime, the given LoadBalancers are outdated then.
+func (m *mgr) swapDiscoveredLoadBalancers(discoveryTag string, lbs []string) (added, removed []string) {
+	m.sync.Lock()
+	defer m.sync.Unlock()
+
+	state := m.state.Load()
+	if state.discoveryTag != discoveryTag {
+		m.logger.Info("Discovery tag changed while discovering LoadBalancers, dropping the result",
+			"discovered-with", discoveryTag,
+			"current", state.discoveryTag,
+		)
+
+		return nil, nil
+	}
+
+	added, removed = diffLoadBalancers(state.loadBalancers, lbs)
+	if len(added) == 0 && len(removed) == 0 {
+		return nil, nil
+	}
+
+	newState := *state
+	newState.loadBalancers = lbs
+
+	// LoadBalancers tagged again do not have to be drained anymore
+	newState.pendingDrains = slices.DeleteFunc(slices.Clone(state.pendingDrains), func(lb string) bool {
+		return slices.Contains(lbs, lb)
+	})
+	newState.pendingDrains = append(newState.pendingDrains, removed...)
+
+	m.state.Store(&newState)
+
+	return added, removed
+}
+
+// markDrained removes the given LoadBalancer from the ones to be drained.
+func (m *mgr) markDrained(lb string) {
+	m.sync.Lock()
+	defer m.sync.Unlock()
+
+	state := m.state.Load()
+
+	newState := *state
+	newState.pendingDrains = slices.DeleteFunc(slices.Clone(state.pendingDrains), func(pending string) bool {
+		return pending == lb
+	})
+
+	m.state.Store(&newState)
+}
+
+// drainLoadBalancer removes the resources of all LoadBalancer Services in the cluster from the given LBaaS
+// LoadBalancer. Every Service is drained on its own, not blocking reconciliations of other Services for long, and
+// draining continues with the next Service when one fails, returning all errors.
+func (m *mgr) drainLoadBalancer(ctx context.Context, lb string) error {
+	if m.k8s == nil {
+		return fmt.Errorf("no usable kubernetes client to list services to drain from LoadBalancer %q", lb)
+	}
+
+	svcList, err := m.k8s.CoreV1().Services("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing services to drain: %w", err)
+	}
+
+	var errs []error
+
+	for _, svc := range svcList.Items {
+		if svc.Spec.Type != v1.ServiceTypeLoadBalancer {
+			continue
+		}
+
+		if err := m.drainService(ctx, lb, &svc); err != nil {
+			errs = append(errs, fmt.Errorf("error draining service %s/%s: %w", svc.Namespace, svc.Name, err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	m.logger.Info("Drained LoadBalancer no longer tagged", "loadbalancer", lb)
+
+	return nil
+}
+
+// drainService removes the resources of the given Service from the given LBaaS LoadBalancer.
+func (m *mgr) drainService(ctx context.Context, lb string, svc *v1.Service) error {
+	m.sync.Lock()
+	defer m.sync.Unlock()
+
+	ctx, clusterName := m.prepare(ctx, m.clusterName, svc)
+
+	recon, err := reconciliation.New(
+		ctx,
+		m.api,
+
+		m.GetLoadBalancerName(ctx, clusterName, svc),
+		lb,
+		string(svc.UID),
+
+		[]net.IP{},
+		map[string]reconciliation.Port{},
+		[]reconciliation.Server{},
+
+		m.state.Load().backoffSteps,
+
+		m.metrics.ForService(svc.Namespace, svc.Name),
+	)
+	if err != nil {
+		return err
+	}
+
+	return recon.Reconcile()
+}
+
+// diffLoadBalancers returns the LoadBalancers only in next (added) and only in prev (removed).
+func diffLoadBalancers(prev, next []string) (added, removed []string) {
+	for _, lb := range next {
+		if !slices.Contains(prev, lb) {
+			added = append(added, lb)
+		}
+	}
+
+	for _, lb := range prev {
+		if !slices.Contains(next, lb) {
+			removed = append(removed, lb)
+		}
+	}
+
+	return added, removed
+}
@@ -0,0 +1,174 @@
+package loadbalancer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.anx.io/go-anxcloud/pkg/api/types"
+	corev1 "go.anx.io/go-anxcloud/pkg/apis/core/v1"
+	lbaasv1 "go.anx.io/go-anxcloud/pkg/apis/lbaas/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/metrics"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/test/apimock"
+)
+
+func loadBalancerService(namespace, name string) *v1.Service {
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, UID: k8stypes.UID(namespace + "-" + name)},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+	}
+}
+
+var _ = Describe("rediscovery", func() {
+	const discoveryTag = "kubernetes-lb-cluster"
+
+	var genericClient *apimock.MockAPI
+	var k8sClient *fake.Clientset
+	var m *mgr
+
+	BeforeEach(func() {
+		genericClient = apimock.NewMockAPI(gomock.NewController(GinkgoT()))
+		k8sClient = fake.NewSimpleClientset(
+			loadBalancerService("default", "web"),
+			loadBalancerService("default", "db"),
+		)
+
+		m = &mgr{
+			api:     genericClient,
+			k8s:     k8sClient,
+			logger:  logr.Discard(),
+			state:   &atomic.Pointer[lbState]{},
+			sync:    &sync.Mutex{},
+			metrics: metrics.NewProviderMetrics("anexia", "0.0.0-unit-tests"),
+		}
+
+		m.state.Store(&lbState{
+			loadBalancers:     []string{"lb-1"},
+			backoffSteps:      1,
+			discoveryTag:      discoveryTag,
+			discoveryInterval: time.Minute,
+		})
+	})
+
+	expectDiscovered := func(identifiers ...string) {
+		genericClient.EXPECT().List(gomock.Any(), &corev1.Resource{Tags: []string{discoveryTag}}, gomock.Any()).
+			DoAndReturn(apimock.ListResources(identifiers...))
+
+		for _, identifier := range identifiers {
+			genericClient.EXPECT().Get(gomock.Any(), &lbaasv1.LoadBalancer{Identifier: identifier}).Return(nil)
+		}
+	}
+
+	It("adds newly discovered LoadBalancers and requeues all Services", func() {
+		expectDiscovered("lb-1", "lb-2")
+
+		Expect(m.rediscover(context.TODO())).To(Succeed())
+		Expect(m.state.Load().loadBalancers).To(Equal([]string{"lb-1", "lb-2"}))
+
+		svc, err := k8sClient.CoreV1().Services("default").Get(context.TODO(), "web", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(svc.Annotations).To(HaveKey(annotationRequeuedAt))
+	})
+
+	It("drops the result when the discovery tag changed while discovering", func() {
+		genericClient.EXPECT().List(gomock.Any(), &corev1.Resource{Tags: []string{discoveryTag}}, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, filter types.FilterObject, opts ...types.ListOption) error {
+				// a configuration reload finishing while we discover
+				m.state.Store(&lbState{
+					loadBalancers:     []string{"lb-reloaded"},
+					backoffSteps:      1,
+					discoveryTag:      "kubernetes-lb-reloaded",
+					discoveryInterval: time.Minute,
+				})
+
+				return apimock.ListResources("lb-2")(ctx, filter, opts...)
+			})
+		genericClient.EXPECT().Get(gomock.Any(), &lbaasv1.LoadBalancer{Identifier: "lb-2"}).Return(nil)
+
+		Expect(m.rediscover(context.TODO())).To(Succeed())
+		Expect(m.state.Load().loadBalancers).To(Equal([]string{"lb-reloaded"}))
+	})
+
+	It("keeps the current LoadBalancers when none are discovered", func() {
+		expectDiscovered()
+
+		Expect(m.rediscover(context.TODO())).To(MatchError(ErrNoLoadBalancers))
+		Expect(m.state.Load().loadBalancers).To(Equal([]string{"lb-1"}))
+	})
+
+	It("removes LoadBalancers no longer tagged, draining them", func() {
+		state := *m.state.Load()
+		state.loadBalancers = []string{"lb-1", "lb-2"}
+		m.state.Store(&state)
+
+		expectDiscovered("lb-1")
+		genericClient.EXPECT().Get(gomock.Any(), &lbaasv1.LoadBalancer{Identifier: "lb-2"}).
+			Return(errors.New("engine unavailable")).Times(2)
+
+		Expect(m.rediscover(context.TODO())).To(Succeed())
+		Expect(m.state.Load().loadBalancers).To(Equal([]string{"lb-1"}))
+		Expect(m.state.Load().pendingDrains).To(Equal([]string{"lb-2"}))
+	})
+
+	It("retries draining on every rediscovery until it succeeded", func() {
+		state := *m.state.Load()
+		state.pendingDrains = []string{"lb-2"}
+		m.state.Store(&state)
+
+		expectDiscovered("lb-1")
+		genericClient.EXPECT().Get(gomock.Any(), &lbaasv1.LoadBalancer{Identifier: "lb-2"}).
+			Return(errors.New("engine unavailable")).Times(2)
+
+		Expect(m.rediscover(context.TODO())).To(Succeed())
+		Expect(m.state.Load().pendingDrains).To(Equal([]string{"lb-2"}))
+
+		// nothing left to drain from lb-2
+		Expect(k8sClient.CoreV1().Services("default").Delete(context.TODO(), "web", metav1.DeleteOptions{})).To(Succeed())
+		Expect(k8sClient.CoreV1().Services("default").Delete(context.TODO(), "db", metav1.DeleteOptions{})).To(Succeed())
+
+		expectDiscovered("lb-1")
+
+		Expect(m.rediscover(context.TODO())).To(Succeed())
+		Expect(m.state.Load().pendingDrains).To(BeEmpty())
+	})
+
+	It("does not drain LoadBalancers tagged again", func() {
+		state := *m.state.Load()
+		state.pendingDrains = []string{"lb-2"}
+		m.state.Store(&state)
+
+		expectDiscovered("lb-1", "lb-2")
+
+		Expect(m.rediscover(context.TODO())).To(Succeed())
+		Expect(m.state.Load().loadBalancers).To(Equal([]string{"lb-1", "lb-2"}))
+		Expect(m.state.Load().pendingDrains).To(BeEmpty())
+	})
+
+	Context("draining", func() {
+		It("continues with the next Service when draining one fails, returning all errors", func() {
+			genericClient.EXPECT().Get(gomock.Any(), &lbaasv1.LoadBalancer{Identifier: "lb-1"}).
+				DoAndReturn(func(context.Context, types.IdentifiedObject, ...types.GetOption) error {
+					// reconciliations of other Services wait only while a Service is drained
+					Expect(m.sync.TryLock()).To(BeFalse())
+					return errors.New("engine unavailable")
+				}).Times(2)
+
+			err := m.drainLoadBalancer(context.TODO(), "lb-1")
+			Expect(err).To(MatchError(ContainSubstring("default/web")))
+			Expect(err).To(MatchError(ContainSubstring("default/db")))
+
+			Expect(m.sync.TryLock()).To(BeTrue())
+		})
+	})
+})
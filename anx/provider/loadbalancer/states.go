@@ -0,0 +1,76 @@
+package loadbalancer
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/loadbalancer/inventory"
+)
+
+// stateCollectionDisabledRecheckInterval is used to check again if state collection got enabled by a configuration
+// reload.
+const stateCollectionDisabledRecheckInterval = 1 * time.Minute
+
+// StateCollector is implemented by LoadBalancer managers able to collect the states of LBaaS resources as metrics.
+type StateCollector interface {
+	// RunStateCollection collects the states of LBaaS resources in the configured interval until ctx is done.
+	RunStateCollection(ctx context.Context)
+}
+
+// RunStateCollection counts the LBaaS resources on all configured LoadBalancers by type and state in the configured
+// interval, until ctx is done. This keeps the LoadBalancerResources metric fresh independent of Service events,
+// allowing to alert on failed resources.
+//
+// Nothing is done while state collection is disabled.
+func (m *mgr) RunStateCollection(ctx context.Context) {
+	collected := sets.New[string]()
+
+	for {
+		interval := m.state.Load().stateInterval
+		if interval > 0 {
+			collected = m.collectStates(ctx, collected)
+		} else {
+			interval = stateCollectionDisabledRecheckInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// collectStates updates the LoadBalancerResources metric for all configured LoadBalancers, removing it for the
+// previously collected LoadBalancers no longer configured. It returns the LoadBalancers now collected.
+func (m *mgr) collectStates(ctx context.Context, previous sets.Set[string]) sets.Set[string] {
+	loadBalancers := sets.New(m.state.Load().loadBalancers...)
+
+	for lb := range loadBalancers {
+		counts, err := inventory.Collect(ctx, m.api, lb)
+		if err != nil {
+			// keeping the previous values, better stale than reporting everything fine
+			m.logger.Error(err, "Error collecting states of LBaaS resources", "loadbalancer", lb)
+			continue
+		}
+
+		for _, resourceType := range inventory.Types {
+			for _, state := range inventory.States {
+				count := counts[inventory.Key{Type: resourceType, State: state}]
+				m.metrics.LoadBalancerResources.WithLabelValues(lb, resourceType, state).Set(float64(count))
+			}
+		}
+	}
+
+	for lb := range previous.Difference(loadBalancers) {
+		for _, resourceType := range inventory.Types {
+			for _, state := range inventory.States {
+				m.metrics.LoadBalancerResources.Delete(map[string]string{"loadbalancer": lb, "type": resourceType, "state": state})
+			}
+		}
+	}
+
+	return loadBalancers
+}
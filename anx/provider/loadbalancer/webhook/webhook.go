@@ -0,0 +1,138 @@
+// Package webhook implements a validating admission webhook rejecting LoadBalancer Services the CCM would fail to
+// reconcile, reporting invalid lbaas.anx.io annotations when the Service is applied instead of when it is reconciled.
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/loadbalancer"
+)
+
+// maxRequestSize limits the size of AdmissionReview requests read, the API server sends at most 3MiB
+const maxRequestSize = 3 * 1024 * 1024
+
+// annotationPrefix is the prefix of the annotations validated
+const annotationPrefix = "lbaas.anx.io/"
+
+// Handler serves AdmissionReview requests for Services.
+type Handler struct {
+	logger logr.Logger
+}
+
+// NewHandler creates a Handler logging to the given logger.
+func NewHandler(logger logr.Logger) *Handler {
+	return &Handler{logger: logger}
+}
+
+// ServeHTTP reads an AdmissionReview from the request and responds with it, rejecting invalid Services.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error reading request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var review admissionv1.AdmissionReview
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(w, "request is not an AdmissionReview", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = h.review(review.Request)
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&review); err != nil {
+		h.logger.Error(err, "Error writing AdmissionReview response")
+	}
+}
+
+// review validates the Service of the request, allowing everything the CCM does not reconcile.
+func (h *Handler) review(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	response := &admissionv1.AdmissionResponse{UID: req.UID, Allowed: true}
+
+	// nothing to validate on deletion
+	if len(req.Object.Raw) == 0 {
+		return response
+	}
+
+	var svc v1.Service
+	if err := json.Unmarshal(req.Object.Raw, &svc); err != nil {
+		response.Allowed = false
+		response.Result = &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("error decoding Service: %v", err),
+		}
+
+		return response
+	}
+
+	// Services of other types or with a LoadBalancer class are not reconciled by the CCM
+	if svc.Spec.Type != v1.ServiceTypeLoadBalancer || svc.Spec.LoadBalancerClass != nil {
+		return response
+	}
+
+	// the CCM has to remove its finalizer from Services being deleted, even when they are invalid
+	if svc.DeletionTimestamp != nil {
+		return response
+	}
+
+	// updates not changing anything validated are allowed, Services might have become invalid by an update of the CCM
+	if len(req.OldObject.Raw) > 0 {
+		var old v1.Service
+		if err := json.Unmarshal(req.OldObject.Raw, &old); err == nil && !validatedFieldsChanged(&old, &svc) {
+			return response
+		}
+	}
+
+	if err := loadbalancer.ValidateService(&svc); err != nil {
+		h.logger.V(1).Info("Rejecting invalid Service", "namespace", req.Namespace, "name", req.Name, "error", err.Error())
+
+		response.Allowed = false
+		response.Result = &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusUnprocessableEntity,
+			Reason:  metav1.StatusReasonInvalid,
+			Message: err.Error(),
+		}
+	}
+
+	return response
+}
+
+// validatedFieldsChanged returns true if anything validated by loadbalancer.ValidateService differs between the given
+// Services.
+func validatedFieldsChanged(old, svc *v1.Service) bool {
+	return old.Spec.Type != svc.Spec.Type ||
+		!equality.Semantic.DeepEqual(old.Spec.Ports, svc.Spec.Ports) ||
+		!slices.Equal(old.Spec.IPFamilies, svc.Spec.IPFamilies) ||
+		!maps.Equal(validatedAnnotations(old), validatedAnnotations(svc))
+}
+
+func validatedAnnotations(svc *v1.Service) map[string]string {
+	return maps.Collect(func(yield func(string, string) bool) {
+		for key, value := range svc.Annotations {
+			if strings.HasPrefix(key, annotationPrefix) && !yield(key, value) {
+				return
+			}
+		}
+	})
+}
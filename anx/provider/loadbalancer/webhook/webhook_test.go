@@ -0,0 +1,127 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+)
+
+func TestWebhook(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Admission webhook")
+}
+
+var _ = Describe("Handler", func() {
+	rawExtension := func(svc *v1.Service) runtime.RawExtension {
+		if svc == nil {
+			return runtime.RawExtension{}
+		}
+
+		raw, err := json.Marshal(svc)
+		Expect(err).NotTo(HaveOccurred())
+		return runtime.RawExtension{Raw: raw}
+	}
+
+	serveUpdate := func(old, svc *v1.Service) *admissionv1.AdmissionResponse {
+		review := admissionv1.AdmissionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+			Request: &admissionv1.AdmissionRequest{
+				UID:       "request-uid",
+				Object:    rawExtension(svc),
+				OldObject: rawExtension(old),
+			},
+		}
+
+		body, err := json.Marshal(&review)
+		Expect(err).NotTo(HaveOccurred())
+
+		recorder := httptest.NewRecorder()
+		NewHandler(logr.Discard()).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/validate-service", bytes.NewReader(body)))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+
+		var response admissionv1.AdmissionReview
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(Succeed())
+		Expect(response.Kind).To(Equal("AdmissionReview"))
+		Expect(response.Response).NotTo(BeNil())
+		Expect(response.Response.UID).To(BeEquivalentTo("request-uid"))
+
+		return response.Response
+	}
+
+	serve := func(svc *v1.Service) *admissionv1.AdmissionResponse {
+		return serveUpdate(nil, svc)
+	}
+
+	service := func(serviceType v1.ServiceType, families string) *v1.Service {
+		return &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "web",
+				Annotations: map[string]string{"lbaas.anx.io/external-ip-families": families},
+			},
+			Spec: v1.ServiceSpec{Type: serviceType},
+		}
+	}
+
+	It("allows valid LoadBalancer Services", func() {
+		Expect(serve(service(v1.ServiceTypeLoadBalancer, "IPv4")).Allowed).To(BeTrue())
+	})
+
+	It("rejects invalid LoadBalancer Services", func() {
+		response := serve(service(v1.ServiceTypeLoadBalancer, "ipv4"))
+
+		Expect(response.Allowed).To(BeFalse())
+		Expect(response.Result.Message).To(ContainSubstring("ipv4 is not a valid IPFamily"))
+	})
+
+	It("allows Services not reconciled by the CCM", func() {
+		Expect(serve(service(v1.ServiceTypeClusterIP, "ipv4")).Allowed).To(BeTrue())
+
+		svc := service(v1.ServiceTypeLoadBalancer, "ipv4")
+		svc.Spec.LoadBalancerClass = ptr.To("example.com/other")
+		Expect(serve(svc).Allowed).To(BeTrue())
+	})
+
+	It("allows updates of Services being deleted", func() {
+		svc := service(v1.ServiceTypeLoadBalancer, "ipv4")
+		svc.DeletionTimestamp = ptr.To(metav1.Now())
+		svc.Finalizers = nil
+
+		Expect(serveUpdate(service(v1.ServiceTypeLoadBalancer, "ipv4"), svc).Allowed).To(BeTrue())
+	})
+
+	It("allows updates not changing anything validated", func() {
+		old := service(v1.ServiceTypeLoadBalancer, "ipv4")
+		svc := service(v1.ServiceTypeLoadBalancer, "ipv4")
+		svc.Labels = map[string]string{"app": "web"}
+		svc.Annotations["example.com/unrelated"] = "changed"
+
+		Expect(serveUpdate(old, svc).Allowed).To(BeTrue())
+	})
+
+	It("rejects updates changing validated annotations or fields", func() {
+		Expect(serveUpdate(service(v1.ServiceTypeLoadBalancer, "IPv4"), service(v1.ServiceTypeLoadBalancer, "ipv4")).Allowed).To(BeFalse())
+		Expect(serveUpdate(service(v1.ServiceTypeClusterIP, "ipv4"), service(v1.ServiceTypeLoadBalancer, "ipv4")).Allowed).To(BeFalse())
+	})
+
+	It("allows requests without object", func() {
+		Expect(serve(nil).Allowed).To(BeTrue())
+	})
+
+	It("rejects requests not being an AdmissionReview", func() {
+		recorder := httptest.NewRecorder()
+		NewHandler(logr.Discard()).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/validate-service", bytes.NewReader([]byte("{}"))))
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+	})
+})
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// rateLimitTransport counts requests rejected by rate limiting, including the advertised Retry-After. The
+// go-anxcloud MetricReceiver only gets the status code, but not the headers of responses.
+type rateLimitTransport struct {
+	base    http.RoundTripper
+	metrics *ProviderMetrics
+}
+
+// Transport wraps the given RoundTripper to count requests rejected by rate limiting of the Anexia Engine, grouped
+// like the metrics given to MetricReceiver.
+func (p *ProviderMetrics) Transport(base http.RoundTripper) http.RoundTripper {
+	return &rateLimitTransport{base: base, metrics: p}
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.base.RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusTooManyRequests {
+		return res, err
+	}
+
+	resource := filterResourceLabel(req.URL.Path)
+
+	t.metrics.HttpClientRateLimitedTotal.WithLabelValues(resource, req.Method).Inc()
+
+	if retryAfter, ok := parseRetryAfter(res.Header.Get("Retry-After"), time.Now()); ok {
+		t.metrics.HttpClientRateLimitRetryAfterTotal.WithLabelValues(resource, req.Method).Add(retryAfter.Seconds())
+	}
+
+	return res, nil
+}
+
+// parseRetryAfter parses the value of a Retry-After header, given either in seconds or as HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	anxclient "go.anx.io/go-anxcloud/pkg/client"
+	kubemetrics "k8s.io/component-base/metrics"
+)
+
+var _ = Describe("Engine requests", func() {
+	var providerMetrics ProviderMetrics
+	var registry kubemetrics.KubeRegistry
+
+	BeforeEach(func() {
+		providerMetrics = NewProviderMetrics("anexia", "0.0.0-unit-tests")
+
+		registry = kubemetrics.NewKubeRegistry()
+		registry.MustRegister(providerMetrics.HttpClientRequestDuration)
+		registry.MustRegister(providerMetrics.HttpClientRateLimitedTotal)
+		registry.MustRegister(providerMetrics.HttpClientRateLimitRetryAfterTotal)
+	})
+
+	It("records request durations by resource and method", func() {
+		providerMetrics.MetricReceiver(
+			map[anxclient.Metric]float64{anxclient.MetricRequestDuration: 0.3},
+			map[anxclient.MetricLabel]string{
+				anxclient.MetricLabelResource: "/api/LBaaS/v1/backend.json/some-identifier",
+				anxclient.MetricLabelMethod:   http.MethodGet,
+				anxclient.MetricLabelStatus:   "200",
+			},
+		)
+
+		Expect(testutil.GatherAndCompare(registry, strings.NewReader(`
+		# HELP cloud_provider_anexia_http_client_request_duration_seconds [ALPHA] Histogram of durations of requests sent to Anexia Engine
+		# TYPE cloud_provider_anexia_http_client_request_duration_seconds histogram
+		cloud_provider_anexia_http_client_request_duration_seconds_bucket{method="GET",resource="backend.json",le="0.05"} 0
+		cloud_provider_anexia_http_client_request_duration_seconds_bucket{method="GET",resource="backend.json",le="0.1"} 0
+		cloud_provider_anexia_http_client_request_duration_seconds_bucket{method="GET",resource="backend.json",le="0.2"} 0
+		cloud_provider_anexia_http_client_request_duration_seconds_bucket{method="GET",resource="backend.json",le="0.4"} 1
+		cloud_provider_anexia_http_client_request_duration_seconds_bucket{method="GET",resource="backend.json",le="0.8"} 1
+		cloud_provider_anexia_http_client_request_duration_seconds_bucket{method="GET",resource="backend.json",le="1.6"} 1
+		cloud_provider_anexia_http_client_request_duration_seconds_bucket{method="GET",resource="backend.json",le="3.2"} 1
+		cloud_provider_anexia_http_client_request_duration_seconds_bucket{method="GET",resource="backend.json",le="6.4"} 1
+		cloud_provider_anexia_http_client_request_duration_seconds_bucket{method="GET",resource="backend.json",le="12.8"} 1
+		cloud_provider_anexia_http_client_request_duration_seconds_bucket{method="GET",resource="backend.json",le="25.6"} 1
+		cloud_provider_anexia_http_client_request_duration_seconds_bucket{method="GET",resource="backend.json",le="+Inf"} 1
+		cloud_provider_anexia_http_client_request_duration_seconds_sum{method="GET",resource="backend.json"} 0.3
+		cloud_provider_anexia_http_client_request_duration_seconds_count{method="GET",resource="backend.json"} 1
+		`), "cloud_provider_anexia_http_client_request_duration_seconds")).To(Succeed())
+	})
+
+	It("counts rate limited requests with their advertised Retry-After", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				w.Header().Set("Retry-After", "30")
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+		}))
+		DeferCleanup(server.Close)
+
+		client := http.Client{Transport: providerMetrics.Transport(http.DefaultTransport)}
+
+		for _, method := range []string{http.MethodPost, http.MethodPost, http.MethodGet} {
+			req, err := http.NewRequest(method, server.URL+"/api/LBaaS/v1/server.json", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			res, err := client.Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Body.Close()).To(Succeed())
+		}
+
+		Expect(testutil.GatherAndCompare(registry, strings.NewReader(`
+		# HELP cloud_provider_anexia_http_client_rate_limit_retry_after_seconds_total [ALPHA] Counter of seconds to wait advertised by Anexia Engine via Retry-After when rate limiting requests
+		# TYPE cloud_provider_anexia_http_client_rate_limit_retry_after_seconds_total counter
+		cloud_provider_anexia_http_client_rate_limit_retry_after_seconds_total{method="POST",resource="LBaaS/v1/server.json"} 60
+		# HELP cloud_provider_anexia_http_client_rate_limited_total [ALPHA] Counter of requests sent to Anexia Engine rejected by rate limiting
+		# TYPE cloud_provider_anexia_http_client_rate_limited_total counter
+		cloud_provider_anexia_http_client_rate_limited_total{method="POST",resource="LBaaS/v1/server.json"} 2
+		`),
+			"cloud_provider_anexia_http_client_rate_limited_total",
+			"cloud_provider_anexia_http_client_rate_limit_retry_after_seconds_total",
+		)).To(Succeed())
+	})
+})
+
+var _ = DescribeTable("parseRetryAfter",
+	func(value string, expected time.Duration, expectedOK bool) {
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+		retryAfter, ok := parseRetryAfter(value, now)
+		Expect(ok).To(Equal(expectedOK))
+		Expect(retryAfter).To(Equal(expected))
+	},
+	Entry("seconds", "120", 2*time.Minute, true),
+	Entry("HTTP date", "Mon, 01 Jan 2024 12:00:45 GMT", 45*time.Second, true),
+	Entry("HTTP date in the past", "Mon, 01 Jan 2024 11:00:00 GMT", time.Duration(0), true),
+	Entry("empty", "", time.Duration(0), false),
+	Entry("invalid", "soon", time.Duration(0), false),
+)
@@ -10,6 +10,7 @@ import (
 	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 
+	"k8s.io/apimachinery/pkg/util/sets"
 	k8smetrics "k8s.io/component-base/metrics"
 )
 
@@ -45,6 +46,20 @@ type ProviderMetrics struct {
 	descriptions                          []*prometheus.Desc
 	HttpClientRequestCount                *k8smetrics.CounterVec
 	HttpClientRequestInFlight             *k8smetrics.GaugeVec
+	HttpClientRequestDuration             *k8smetrics.HistogramVec
+	HttpClientRateLimitedTotal            *k8smetrics.CounterVec
+	HttpClientRateLimitRetryAfterTotal    *k8smetrics.CounterVec
+	ConfigReloadsTotal                    *k8smetrics.CounterVec
+	LoadBalancerSecondaryInSync           *k8smetrics.GaugeVec
+	VMCacheRequestsTotal                  *k8smetrics.CounterVec
+	VMUnknownPowerStatesTotal             *k8smetrics.CounterVec
+	LastSuccessfulReconcileTimestamp      *k8smetrics.GaugeVec
+	LoadBalancerResources                 *k8smetrics.GaugeVec
+
+	// serviceLabels is shared by all copies, serviceNamespace and serviceName are set on copies returned by ForService
+	serviceLabels    *serviceLabels
+	serviceNamespace string
+	serviceName      string
 }
 
 func getCounterOpts(metricName string, helpMessage string) *k8smetrics.CounterOpts {
@@ -65,12 +80,12 @@ func getHistogramOpts(metricName string, helpMessage string) *k8smetrics.Histogr
 func setReconcileMetrics(providerMetrics *ProviderMetrics) {
 	providerMetrics.ReconciliationTotalDuration = k8smetrics.NewHistogramVec(
 		getHistogramOpts("reconcile_total_duration_seconds", "Histogram of times spent for one total reconciliation"),
-		[]string{"service"},
+		[]string{"service", "namespace", "name"},
 	)
 
 	providerMetrics.ReconciliationCreateErrorsTotal = k8smetrics.NewCounterVec(
 		getCounterOpts("reconcile_create_errors_total", "Counter of errors while creating resources in a reconciliation"),
-		[]string{"service"},
+		[]string{"service", "namespace", "name"},
 	)
 
 	providerMetrics.ReconciliationDeleteRetriesTotal = k8smetrics.NewCounterVec(
@@ -80,7 +95,7 @@ func setReconcileMetrics(providerMetrics *ProviderMetrics) {
 
 	providerMetrics.ReconciliationDeleteErrorsTotal = k8smetrics.NewCounterVec(
 		getCounterOpts("reconcile_delete_errors_total", "Counter of errors while deleting resources in a reconciliation"),
-		[]string{"service"},
+		[]string{"service", "namespace", "name"},
 	)
 
 	providerMetrics.ReconciliationCreatedTotal = k8smetrics.NewCounterVec(
@@ -101,7 +116,13 @@ func setReconcileMetrics(providerMetrics *ProviderMetrics) {
 	providerMetrics.ReconciliationPendingResources = k8smetrics.NewGaugeVec(&k8smetrics.GaugeOpts{
 		Name: getFQMetricName("reconcile_resources_pending"),
 		Help: "Gauge of pending creation or deletion operations of resources"},
-		[]string{"service", "operation"},
+		[]string{"service", "namespace", "name", "operation"},
+	)
+
+	providerMetrics.LastSuccessfulReconcileTimestamp = k8smetrics.NewGaugeVec(&k8smetrics.GaugeOpts{
+		Name: getFQMetricName("last_successful_reconcile_timestamp_seconds"),
+		Help: "Gauge of the unix timestamp of the last successful reconciliation of a LoadBalancer service"},
+		[]string{"namespace", "name"},
 	)
 
 	providerMetrics.ReconciliationRetrievedResourcesTotal = k8smetrics.NewCounterVec(&k8smetrics.CounterOpts{
@@ -121,6 +142,55 @@ func setReconcileMetrics(providerMetrics *ProviderMetrics) {
 		Help: "Amount of requests sent to Anexia Engine currently waiting for response"},
 		[]string{"resource", "method"},
 	)
+
+	providerMetrics.HttpClientRequestDuration = k8smetrics.NewHistogramVec(&k8smetrics.HistogramOpts{
+		Name:    getFQMetricName("http_client_request_duration_seconds"),
+		Help:    "Histogram of durations of requests sent to Anexia Engine",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 10)},
+		[]string{"resource", "method"},
+	)
+
+	providerMetrics.HttpClientRateLimitedTotal = k8smetrics.NewCounterVec(&k8smetrics.CounterOpts{
+		Name: getFQMetricName("http_client_rate_limited_total"),
+		Help: "Counter of requests sent to Anexia Engine rejected by rate limiting"},
+		[]string{"resource", "method"},
+	)
+
+	providerMetrics.HttpClientRateLimitRetryAfterTotal = k8smetrics.NewCounterVec(&k8smetrics.CounterOpts{
+		Name: getFQMetricName("http_client_rate_limit_retry_after_seconds_total"),
+		Help: "Counter of seconds to wait advertised by Anexia Engine via Retry-After when rate limiting requests"},
+		[]string{"resource", "method"},
+	)
+
+	providerMetrics.ConfigReloadsTotal = k8smetrics.NewCounterVec(&k8smetrics.CounterOpts{
+		Name: getFQMetricName("config_reloads_total"),
+		Help: "Counter of cloud-config reloads grouped by result"},
+		[]string{"result"},
+	)
+
+	providerMetrics.LoadBalancerSecondaryInSync = k8smetrics.NewGaugeVec(&k8smetrics.GaugeOpts{
+		Name: getFQMetricName("loadbalancer_secondary_in_sync"),
+		Help: "Gauge if a secondary LBaaS LoadBalancer has the same state as the primary one for the last checked service"},
+		[]string{"loadbalancer"},
+	)
+
+	providerMetrics.LoadBalancerResources = k8smetrics.NewGaugeVec(&k8smetrics.GaugeOpts{
+		Name: getFQMetricName("loadbalancer_resources"),
+		Help: "Gauge of LBaaS resources configured on a LoadBalancer grouped by type and state (ok, pending, updating or error)"},
+		[]string{"loadbalancer", "type", "state"},
+	)
+
+	providerMetrics.VMCacheRequestsTotal = k8smetrics.NewCounterVec(&k8smetrics.CounterOpts{
+		Name: getFQMetricName("vm_cache_requests_total"),
+		Help: "Counter of VM info, power state and tag lookups grouped by cache and result (hit or miss)"},
+		[]string{"cache", "result"},
+	)
+
+	providerMetrics.VMUnknownPowerStatesTotal = k8smetrics.NewCounterVec(&k8smetrics.CounterOpts{
+		Name: getFQMetricName("vm_unknown_power_states_total"),
+		Help: "Counter of power states reported for VMs not known to the provider"},
+		[]string{"state"},
+	)
 }
 
 // NewProviderMetrics returns a prometheus.Collector for Provider Metrics.
@@ -138,6 +208,7 @@ func NewProviderMetrics(providerName, providerVersion string) ProviderMetrics {
 		descriptions:    description,
 		m:               &sync.RWMutex{},
 		featureState:    map[string]prometheus.Metric{},
+		serviceLabels:   &serviceLabels{services: sets.New[string]()},
 	}
 
 	setReconcileMetrics(&providerMetrics)
@@ -211,6 +282,8 @@ func (p *ProviderMetrics) MetricReceiver(metrics map[anxclient.Metric]float64, l
 			p.HttpClientRequestCount.WithLabelValues(resource, method, status).Add(value)
 		case anxclient.MetricRequestInflight:
 			p.HttpClientRequestInFlight.WithLabelValues(resource, method).Add(value)
+		case anxclient.MetricRequestDuration:
+			p.HttpClientRequestDuration.WithLabelValues(resource, method).Observe(value)
 		}
 	}
 }
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const serviceLabelValue = "lbaas"
+
+// serviceLabels tracks the Services reconciliation metrics are labelled with, bounding the cardinality of the
+// metrics to at most limit Services.
+type serviceLabels struct {
+	m        sync.Mutex
+	limit    int
+	services sets.Set[string]
+}
+
+// SetServiceLabelLimit enables labelling reconciliation metrics with namespace and name of the Service for up to
+// limit Services, 0 disables these labels. Metrics of further Services are recorded with empty namespace and name.
+func (p *ProviderMetrics) SetServiceLabelLimit(limit int) {
+	p.serviceLabels.m.Lock()
+	defer p.serviceLabels.m.Unlock()
+
+	p.serviceLabels.limit = limit
+}
+
+// ForService returns a copy of the ProviderMetrics recording reconciliation metrics for the given Service, labelled
+// with its namespace and name if enabled and the limit of labelled Services is not reached yet.
+func (p ProviderMetrics) ForService(namespace, name string) ProviderMetrics {
+	if p.serviceLabels == nil {
+		return p
+	}
+
+	p.serviceLabels.m.Lock()
+	defer p.serviceLabels.m.Unlock()
+
+	key := namespace + "/" + name
+	if !p.serviceLabels.services.Has(key) {
+		if p.serviceLabels.services.Len() >= p.serviceLabels.limit {
+			return p
+		}
+
+		p.serviceLabels.services.Insert(key)
+	}
+
+	p.serviceNamespace = namespace
+	p.serviceName = name
+
+	return p
+}
+
+// ServiceLabelValues returns the label values of reconciliation metrics for the Service given to ForService,
+// followed by the given values.
+func (p ProviderMetrics) ServiceLabelValues(values ...string) []string {
+	return append([]string{serviceLabelValue, p.serviceNamespace, p.serviceName}, values...)
+}
+
+// MarkReconciled records the current time as last successful reconciliation of the Service given to ForService.
+// Nothing is recorded for Services not labelled, as a timestamp shared by all of them tells nothing.
+func (p ProviderMetrics) MarkReconciled() {
+	if p.serviceName == "" {
+		return
+	}
+
+	p.LastSuccessfulReconcileTimestamp.WithLabelValues(p.serviceNamespace, p.serviceName).SetToCurrentTime()
+}
+
+// ForgetService removes the metrics labelled with the given Service, e.g. after it was deleted, making room for
+// another Service to be labelled.
+func (p ProviderMetrics) ForgetService(namespace, name string) {
+	if p.serviceLabels == nil {
+		return
+	}
+
+	p.serviceLabels.m.Lock()
+	defer p.serviceLabels.m.Unlock()
+
+	key := namespace + "/" + name
+	if !p.serviceLabels.services.Has(key) {
+		return
+	}
+
+	p.serviceLabels.services.Delete(key)
+
+	labels := map[string]string{"service": serviceLabelValue, "namespace": namespace, "name": name}
+	p.ReconciliationTotalDuration.Delete(labels)
+	p.ReconciliationCreateErrorsTotal.Delete(labels)
+	p.ReconciliationDeleteErrorsTotal.Delete(labels)
+
+	for _, operation := range []string{"create", "destroy"} {
+		p.ReconciliationPendingResources.Delete(map[string]string{
+			"service": serviceLabelValue, "namespace": namespace, "name": name, "operation": operation,
+		})
+	}
+
+	p.LastSuccessfulReconcileTimestamp.Delete(map[string]string{"namespace": namespace, "name": name})
+}
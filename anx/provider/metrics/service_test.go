@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	kubemetrics "k8s.io/component-base/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Provider metrics")
+}
+
+var _ = Describe("Service labels", func() {
+	var providerMetrics ProviderMetrics
+	var registry kubemetrics.KubeRegistry
+
+	BeforeEach(func() {
+		providerMetrics = NewProviderMetrics("anexia", "0.0.0-unit-tests")
+
+		registry = kubemetrics.NewKubeRegistry()
+		registry.MustRegister(providerMetrics.ReconciliationCreateErrorsTotal)
+		registry.MustRegister(providerMetrics.LastSuccessfulReconcileTimestamp)
+	})
+
+	It("does not label metrics by default", func() {
+		Expect(providerMetrics.ForService("default", "web").ServiceLabelValues("create")).To(Equal([]string{"lbaas", "", "", "create"}))
+	})
+
+	It("labels metrics of up to limit Services", func() {
+		providerMetrics.SetServiceLabelLimit(2)
+
+		for _, name := range []string{"web", "db", "web", "cache"} {
+			m := providerMetrics.ForService("default", name)
+			m.ReconciliationCreateErrorsTotal.WithLabelValues(m.ServiceLabelValues()...).Inc()
+		}
+
+		Expect(testutil.GatherAndCompare(registry, strings.NewReader(`
+		# HELP cloud_provider_anexia_reconcile_create_errors_total [ALPHA] Counter of errors while creating resources in a reconciliation
+		# TYPE cloud_provider_anexia_reconcile_create_errors_total counter
+		cloud_provider_anexia_reconcile_create_errors_total{name="",namespace="",service="lbaas"} 1
+		cloud_provider_anexia_reconcile_create_errors_total{name="db",namespace="default",service="lbaas"} 1
+		cloud_provider_anexia_reconcile_create_errors_total{name="web",namespace="default",service="lbaas"} 2
+		`), "cloud_provider_anexia_reconcile_create_errors_total")).To(Succeed())
+	})
+
+	It("records the last successful reconciliation only for labelled Services", func() {
+		providerMetrics.SetServiceLabelLimit(1)
+
+		providerMetrics.ForService("default", "web").MarkReconciled()
+		providerMetrics.ForService("default", "db").MarkReconciled()
+
+		count, err := testutil.GatherAndCount(registry, "cloud_provider_anexia_last_successful_reconcile_timestamp_seconds")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(1))
+	})
+
+	It("records no last successful reconciliation without labels", func() {
+		providerMetrics.ForService("default", "web").MarkReconciled()
+
+		count, err := testutil.GatherAndCount(registry, "cloud_provider_anexia_last_successful_reconcile_timestamp_seconds")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(BeZero())
+	})
+
+	It("removes metrics of forgotten Services and labels the next Service instead", func() {
+		providerMetrics.SetServiceLabelLimit(1)
+
+		m := providerMetrics.ForService("default", "web")
+		m.ReconciliationCreateErrorsTotal.WithLabelValues(m.ServiceLabelValues()...).Inc()
+		m.MarkReconciled()
+
+		providerMetrics.ForgetService("default", "web")
+
+		Expect(testutil.GatherAndCompare(registry, strings.NewReader(""),
+			"cloud_provider_anexia_reconcile_create_errors_total",
+			"cloud_provider_anexia_last_successful_reconcile_timestamp_seconds",
+		)).To(Succeed())
+		Expect(providerMetrics.ForService("default", "db").ServiceLabelValues()).To(Equal([]string{"lbaas", "default", "db"}))
+	})
+})
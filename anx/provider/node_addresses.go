@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/configuration"
+	vminfo "go.anx.io/go-anxcloud/pkg/vsphere/info"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// nodeAddressTypeOrder is the order node addresses are returned in, per type.
+var nodeAddressTypeOrder = []v1.NodeAddressType{v1.NodeInternalIP, v1.NodeExternalIP}
+
+// nodeAddresses returns the addresses of the given VM selected by the given networks. Without networks, the IPv4
+// addresses of the first network are used as InternalIP, as done before networks could be configured. IPv6 addresses
+// are only returned when selected by a configured network.
+//
+// Addresses are ordered by type (InternalIP before ExternalIP) and, per type, IPv4 before IPv6. Kubernetes uses the
+// first address of each family, making IPv4 the primary family of dual-stack nodes. Inside each group, addresses are
+// ordered as the networks are configured and the VM reports them. Addresses selected by multiple networks are only
+// returned once, with the type of the first network selecting it. Link-local addresses are never returned.
+func nodeAddresses(info vminfo.Info, networks []configuration.NodeNetwork) []v1.NodeAddress {
+	if len(networks) == 0 {
+		if len(info.Network) == 0 {
+			return nil
+		}
+
+		ret := make([]v1.NodeAddress, 0, len(info.Network[0].IPv4))
+		for _, address := range info.Network[0].IPv4 {
+			ret = append(ret, v1.NodeAddress{Type: v1.NodeInternalIP, Address: address})
+		}
+
+		return ret
+	}
+
+	type group struct {
+		addressType v1.NodeAddressType
+		ipv6        bool
+	}
+
+	groups := make(map[group][]string)
+	seen := make(map[string]bool)
+
+	for _, network := range networks {
+		addressType := network.Type
+		if addressType == "" {
+			addressType = v1.NodeInternalIP
+		}
+
+		var cidr *net.IPNet
+		if network.CIDR != "" {
+			// invalid CIDRs are rejected when validating the config
+			_, cidr, _ = net.ParseCIDR(network.CIDR)
+		}
+
+		for _, vmNetwork := range info.Network {
+			if network.VLAN != "" && network.VLAN != vmNetwork.VLAN {
+				continue
+			}
+
+			for _, address := range append(slices.Clone(vmNetwork.IPv4), vmNetwork.IPv6...) {
+				ip := net.ParseIP(address)
+				if ip == nil || ip.IsLinkLocalUnicast() || seen[ip.String()] {
+					continue
+				}
+
+				if cidr != nil && !cidr.Contains(ip) {
+					continue
+				}
+
+				seen[ip.String()] = true
+
+				g := group{addressType: addressType, ipv6: ip.To4() == nil}
+				groups[g] = append(groups[g], ip.String())
+			}
+		}
+	}
+
+	ret := make([]v1.NodeAddress, 0, len(seen))
+
+	for _, addressType := range nodeAddressTypeOrder {
+		for _, ipv6 := range []bool{false, true} {
+			for _, address := range groups[group{addressType: addressType, ipv6: ipv6}] {
+				ret = append(ret, v1.NodeAddress{Type: addressType, Address: address})
+			}
+		}
+	}
+
+	return ret
+}
+
+// nodeHostnameAddresses returns the Hostname, InternalDNS and ExternalDNS addresses of the given VM, as enabled in
+// the given config. All of them are based on the VM name without the customer prefix, no addresses are returned when
+// that is not a valid DNS name.
+func nodeHostnameAddresses(info vminfo.Info, config *configuration.ProviderConfig) []v1.NodeAddress {
+	hostname := strings.ToLower(info.Name)
+	if config.CustomerID != "" {
+		hostname = strings.TrimPrefix(hostname, strings.ToLower(config.CustomerID)+"-")
+	}
+
+	if len(validation.IsDNS1123Subdomain(hostname)) > 0 {
+		return nil
+	}
+
+	ret := make([]v1.NodeAddress, 0, 3)
+
+	if config.NodeHostnameAddress {
+		ret = append(ret, v1.NodeAddress{Type: v1.NodeHostName, Address: hostname})
+	}
+
+	if config.NodeInternalDNSSuffix != "" {
+		ret = append(ret, v1.NodeAddress{Type: v1.NodeInternalDNS, Address: fmt.Sprintf("%s.%s", hostname, config.NodeInternalDNSSuffix)})
+	}
+
+	if config.NodeExternalDNSSuffix != "" {
+		ret = append(ret, v1.NodeAddress{Type: v1.NodeExternalDNS, Address: fmt.Sprintf("%s.%s", hostname, config.NodeExternalDNSSuffix)})
+	}
+
+	return ret
+}
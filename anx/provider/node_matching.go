@@ -0,0 +1,176 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/configuration"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/utils"
+	"github.com/go-logr/logr"
+	"go.anx.io/go-anxcloud/pkg/api"
+	"go.anx.io/go-anxcloud/pkg/api/types"
+	corev1 "go.anx.io/go-anxcloud/pkg/apis/core/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+var errUnknownNodeMatchingStrategy = errors.New("unknown node matching strategy")
+
+// nodeMatcher returns the identifiers of the VMs matching the given Node.
+type nodeMatcher func(i *instanceManager, ctx context.Context, node *v1.Node) ([]string, error)
+
+var nodeMatchers = map[configuration.NodeMatchingStrategy]nodeMatcher{
+	configuration.NodeMatchingStrategyName:  (*instanceManager).matchNodeByName,
+	configuration.NodeMatchingStrategyTag:   (*instanceManager).matchNodeByTag,
+	configuration.NodeMatchingStrategyLabel: (*instanceManager).matchNodeByLabel,
+	configuration.NodeMatchingStrategyIP:    (*instanceManager).matchNodeByIP,
+}
+
+// matchNode applies the configured node matching strategies in order, returning the identifier of the VM found by
+// the first strategy finding exactly one.
+func (i *instanceManager) matchNode(ctx context.Context, node *v1.Node) (string, error) {
+	logger := logr.FromContextOrDiscard(ctx).WithValues("nodeName", node.Name)
+
+	strategies := i.Config().NodeMatchingStrategies
+	if len(strategies) == 0 {
+		strategies = []configuration.NodeMatchingStrategy{configuration.NodeMatchingStrategyName}
+	}
+
+	ambiguous := false
+
+	for _, strategy := range strategies {
+		matcher, ok := nodeMatchers[strategy]
+		if !ok {
+			return "", fmt.Errorf("%w %q", errUnknownNodeMatchingStrategy, strategy)
+		}
+
+		vms, err := matcher(i, ctx, node)
+		if err != nil {
+			return "", fmt.Errorf("error matching node by %s: %w", strategy, err)
+		}
+
+		switch len(vms) {
+		case 0:
+			logger.V(1).Info("No VM matched Node", "strategy", strategy)
+		case 1:
+			logger.V(1).Info("Matched Node to VM", "strategy", strategy, "identifier", vms[0])
+			return vms[0], nil
+		default:
+			logger.Info("Multiple VMs matched Node, trying next strategy", "strategy", strategy, "identifiers", vms)
+			ambiguous = true
+		}
+	}
+
+	if ambiguous {
+		return "", errVirtualMachineNameNotUnique
+	}
+
+	return "", errNamedVirtualMachineNotFound
+}
+
+// matchNodeByName searches VMs by the Node name, filtering them by the Node IPs when multiple are found.
+func (i *instanceManager) matchNodeByName(ctx context.Context, node *v1.Node) ([]string, error) {
+	vms, err := i.instancesByName(ctx, node.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(vms) > 1 {
+		logr.FromContextOrDiscard(ctx).Info("Found multiple VMs matching node.Name, filtering by IPs now", "nodeName", node.Name)
+		vms = i.filterInstances(ctx, node, vms)
+	}
+
+	return vms, nil
+}
+
+// matchNodeByTag returns the VMs tagged "kubernetes-node-$nodeName".
+func (i *instanceManager) matchNodeByTag(ctx context.Context, node *v1.Node) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	tag := fmt.Sprintf("kubernetes-node-%s", node.Name)
+
+	var oc types.ObjectChannel
+	err := i.GenericClient().List(ctx, &corev1.Resource{Tags: []string{tag}}, api.ObjectChannel(&oc))
+	if err != nil {
+		// nothing is tagged with the tag
+		if utils.HTTPStatusCode(err) == http.StatusUnprocessableEntity {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error listing resources tagged %q: %w", tag, err)
+	}
+
+	ret := make([]string, 0, 1)
+	for retriever := range oc {
+		var res corev1.Resource
+		if err := retriever(&res); err != nil {
+			return nil, fmt.Errorf("error retrieving resource tagged %q: %w", tag, err)
+		}
+
+		ret = append(ret, res.Identifier)
+	}
+
+	return ret, nil
+}
+
+// matchNodeByLabel takes the VM identifier from the configured Node label, usually set via the kubelet. As the
+// kubelet can set any label, the VM is only accepted when it exists and its name or IPs match the Node.
+func (i *instanceManager) matchNodeByLabel(ctx context.Context, node *v1.Node) ([]string, error) {
+	logger := logr.FromContextOrDiscard(ctx).WithValues("nodeName", node.Name)
+
+	identifier := node.Labels[i.Config().NodeMatchingLabel]
+	if identifier == "" {
+		return nil, nil
+	}
+
+	vm, err := i.vmInfo(ctx, identifier)
+	if utils.IsNotFoundError(err) {
+		logger.Info("VM given by Node label does not exist, ignoring it", "identifier", identifier)
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error retrieving VM %q given by Node label: %w", identifier, err)
+	}
+
+	if vm.Name == node.Name || vm.Name == fmt.Sprintf("%s-%s", i.Config().CustomerID, node.Name) {
+		return []string{identifier}, nil
+	}
+
+	if len(i.filterInstances(ctx, node, []string{identifier})) == 1 {
+		return []string{identifier}, nil
+	}
+
+	logger.Info("Neither name nor IPs of the VM given by Node label match the Node, ignoring it", "identifier", identifier, "vmName", vm.Name)
+	return nil, nil
+}
+
+// matchNodeByIP compares the InternalIPs of the Node with the IPs of the VMs with the customer prefix. The info of
+// every VM has to be retrieved for its IPs, which is cached, and the search stops as soon as a second VM matched, as
+// the result is ambiguous then anyway.
+func (i *instanceManager) matchNodeByIP(ctx context.Context, node *v1.Node) ([]string, error) {
+	if len(nodeInternalIPs(node)) == 0 {
+		return nil, nil
+	}
+
+	namePrefix := i.Config().CustomerID
+	if namePrefix == "" {
+		namePrefix = "%"
+	}
+
+	vms, err := i.VSphere().Search().ByName(ctx, fmt.Sprintf("%s-%%", namePrefix))
+	if err != nil {
+		return nil, fmt.Errorf("error listing VMs: %w", err)
+	}
+
+	matched := make([]string, 0, 1)
+	for _, vm := range vms {
+		matched = append(matched, i.filterInstances(ctx, node, []string{vm.Identifier})...)
+
+		if len(matched) > 1 {
+			break
+		}
+	}
+
+	return matched, nil
+}
@@ -0,0 +1,218 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/configuration"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/metrics"
+	tUtils "github.com/anexia-it/k8s-anexia-ccm/anx/provider/test"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.anx.io/go-anxcloud/pkg/client"
+	"go.anx.io/go-anxcloud/pkg/vsphere/info"
+	"go.anx.io/go-anxcloud/pkg/vsphere/search"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNodeMatching(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	const nodeName = "test-node"
+
+	node := func(labels map[string]string, internalIPs ...string) *v1.Node {
+		addresses := make([]v1.NodeAddress, 0, len(internalIPs))
+		for _, ip := range internalIPs {
+			addresses = append(addresses, v1.NodeAddress{Type: v1.NodeInternalIP, Address: ip})
+		}
+
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeName, Labels: labels},
+			Status:     v1.NodeStatus{Addresses: addresses},
+		}
+	}
+
+	t.Run("ByLabel", func(t *testing.T) {
+		t.Parallel()
+		provider := tUtils.GetMockedAnxProvider()
+		provider.ProviderConfig.NodeMatchingStrategies = []configuration.NodeMatchingStrategy{
+			configuration.NodeMatchingStrategyLabel,
+			configuration.NodeMatchingStrategyName,
+		}
+		provider.ProviderConfig.NodeMatchingLabel = "anexia.com/vm-identifier"
+
+		provider.InfoMock.On("Get", ctx, "labeled-vm").Return(info.Info{
+			Identifier: "labeled-vm",
+			Name:       fmt.Sprintf("%s-%s", provider.Config().CustomerID, nodeName),
+		}, nil)
+
+		manager := instanceManager{Provider: provider}
+
+		identifier, err := manager.InstanceIDByNode(ctx, node(map[string]string{"anexia.com/vm-identifier": "labeled-vm"}))
+		require.NoError(t, err)
+		require.Equal(t, "labeled-vm", identifier)
+		provider.SearchMock.AssertNotCalled(t, "ByName", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ByLabel/MatchingIP", func(t *testing.T) {
+		t.Parallel()
+		provider := tUtils.GetMockedAnxProvider()
+		provider.ProviderConfig.NodeMatchingStrategies = []configuration.NodeMatchingStrategy{configuration.NodeMatchingStrategyLabel}
+		provider.ProviderConfig.NodeMatchingLabel = "anexia.com/vm-identifier"
+		provider.InfoMock.On("Get", ctx, "labeled-vm").Return(info.Info{
+			Identifier: "labeled-vm",
+			Name:       "renamed-vm",
+			Network:    []info.Network{{IPv4: []string{"10.0.0.1"}}},
+		}, nil)
+
+		manager := instanceManager{Provider: provider}
+
+		identifier, err := manager.InstanceIDByNode(ctx, node(map[string]string{"anexia.com/vm-identifier": "labeled-vm"}, "10.0.0.1"))
+		require.NoError(t, err)
+		require.Equal(t, "labeled-vm", identifier)
+	})
+
+	t.Run("ByLabel/NotMatchingNode", func(t *testing.T) {
+		t.Parallel()
+		provider := tUtils.GetMockedAnxProvider()
+		provider.ProviderConfig.NodeMatchingStrategies = []configuration.NodeMatchingStrategy{configuration.NodeMatchingStrategyLabel}
+		provider.ProviderConfig.NodeMatchingLabel = "anexia.com/vm-identifier"
+		provider.InfoMock.On("Get", ctx, "other-vm").Return(info.Info{
+			Identifier: "other-vm",
+			Name:       "other-node",
+			Network:    []info.Network{{IPv4: []string{"10.0.0.2"}}},
+		}, nil)
+
+		manager := instanceManager{Provider: provider}
+
+		_, err := manager.InstanceIDByNode(ctx, node(map[string]string{"anexia.com/vm-identifier": "other-vm"}, "10.0.0.1"))
+		require.ErrorIs(t, err, errNamedVirtualMachineNotFound)
+	})
+
+	t.Run("ByLabel/NotExisting", func(t *testing.T) {
+		t.Parallel()
+		provider := tUtils.GetMockedAnxProvider()
+		provider.ProviderConfig.NodeMatchingStrategies = []configuration.NodeMatchingStrategy{configuration.NodeMatchingStrategyLabel}
+		provider.ProviderConfig.NodeMatchingLabel = "anexia.com/vm-identifier"
+		provider.InfoMock.On("Get", ctx, "missing-vm").Return(info.Info{}, &client.ResponseError{
+			Response: &http.Response{StatusCode: http.StatusNotFound},
+		})
+
+		manager := instanceManager{Provider: provider}
+
+		_, err := manager.InstanceIDByNode(ctx, node(map[string]string{"anexia.com/vm-identifier": "missing-vm"}))
+		require.ErrorIs(t, err, errNamedVirtualMachineNotFound)
+	})
+
+	t.Run("ByLabel/FallbackToName", func(t *testing.T) {
+		t.Parallel()
+		provider := tUtils.GetMockedAnxProvider()
+		provider.ProviderConfig.NodeMatchingStrategies = []configuration.NodeMatchingStrategy{
+			configuration.NodeMatchingStrategyLabel,
+			configuration.NodeMatchingStrategyName,
+		}
+		provider.ProviderConfig.NodeMatchingLabel = "anexia.com/vm-identifier"
+		provider.SearchMock.On("ByName", ctx, fmt.Sprintf("%s-%s", provider.Config().CustomerID, nodeName)).
+			Return([]search.VM{{Identifier: "named-vm"}}, nil)
+
+		manager := instanceManager{Provider: provider}
+
+		identifier, err := manager.InstanceIDByNode(ctx, node(nil))
+		require.NoError(t, err)
+		require.Equal(t, "named-vm", identifier)
+	})
+
+	t.Run("ByIP", func(t *testing.T) {
+		t.Parallel()
+		provider := tUtils.GetMockedAnxProvider()
+		provider.ProviderConfig.NodeMatchingStrategies = []configuration.NodeMatchingStrategy{configuration.NodeMatchingStrategyIP}
+		provider.SearchMock.On("ByName", ctx, fmt.Sprintf("%s-%%", provider.Config().CustomerID)).
+			Return([]search.VM{{Identifier: "first-vm"}, {Identifier: "second-vm"}}, nil)
+		provider.InfoMock.On("Get", ctx, "first-vm").Return(info.Info{
+			Identifier: "first-vm",
+			Network:    []info.Network{{IPv4: []string{"10.0.0.1"}}},
+		}, nil)
+		provider.InfoMock.On("Get", ctx, "second-vm").Return(info.Info{
+			Identifier: "second-vm",
+			Network:    []info.Network{{IPv4: []string{"10.0.0.2"}, IPv6: []string{"fd00::2"}}},
+		}, nil)
+
+		manager := instanceManager{Provider: provider}
+
+		identifier, err := manager.InstanceIDByNode(ctx, node(nil, "10.0.0.2", "fd00::2"))
+		require.NoError(t, err)
+		require.Equal(t, "second-vm", identifier)
+	})
+
+	t.Run("ByIP/NotUnique", func(t *testing.T) {
+		t.Parallel()
+		provider := tUtils.GetMockedAnxProvider()
+		provider.ProviderConfig.NodeMatchingStrategies = []configuration.NodeMatchingStrategy{configuration.NodeMatchingStrategyIP}
+		provider.SearchMock.On("ByName", ctx, fmt.Sprintf("%s-%%", provider.Config().CustomerID)).
+			Return([]search.VM{{Identifier: "first-vm"}, {Identifier: "second-vm"}}, nil)
+		provider.InfoMock.On("Get", ctx, "first-vm").Return(info.Info{
+			Identifier: "first-vm",
+			Network:    []info.Network{{IPv4: []string{"10.0.0.1"}}},
+		}, nil)
+		provider.InfoMock.On("Get", ctx, "second-vm").Return(info.Info{
+			Identifier: "second-vm",
+			Network:    []info.Network{{IPv4: []string{"10.0.0.1"}}},
+		}, nil)
+
+		manager := instanceManager{Provider: provider}
+
+		_, err := manager.InstanceIDByNode(ctx, node(nil, "10.0.0.1"))
+		require.ErrorIs(t, err, errVirtualMachineNameNotUnique)
+	})
+
+	t.Run("ByIP/StopsWhenAmbiguous", func(t *testing.T) {
+		t.Parallel()
+		provider := tUtils.GetMockedAnxProvider()
+		provider.ProviderConfig.NodeMatchingStrategies = []configuration.NodeMatchingStrategy{configuration.NodeMatchingStrategyIP}
+		provider.SearchMock.On("ByName", ctx, fmt.Sprintf("%s-%%", provider.Config().CustomerID)).
+			Return([]search.VM{{Identifier: "first-vm"}, {Identifier: "second-vm"}, {Identifier: "third-vm"}}, nil)
+		provider.InfoMock.On("Get", ctx, "first-vm").Return(info.Info{
+			Identifier: "first-vm",
+			Network:    []info.Network{{IPv4: []string{"10.0.0.1"}}},
+		}, nil)
+		provider.InfoMock.On("Get", ctx, "second-vm").Return(info.Info{
+			Identifier: "second-vm",
+			Network:    []info.Network{{IPv4: []string{"10.0.0.1"}}},
+		}, nil)
+
+		manager := instanceManager{Provider: provider}
+
+		_, err := manager.InstanceIDByNode(ctx, node(nil, "10.0.0.1"))
+		require.ErrorIs(t, err, errVirtualMachineNameNotUnique)
+		provider.InfoMock.AssertNotCalled(t, "Get", ctx, "third-vm")
+	})
+
+	t.Run("ByIP/Cached", func(t *testing.T) {
+		t.Parallel()
+		provider := tUtils.GetMockedAnxProvider()
+		provider.ProviderConfig.NodeMatchingStrategies = []configuration.NodeMatchingStrategy{configuration.NodeMatchingStrategyIP}
+		provider.SearchMock.On("ByName", ctx, fmt.Sprintf("%s-%%", provider.Config().CustomerID)).
+			Return([]search.VM{{Identifier: "first-vm"}, {Identifier: "second-vm"}}, nil)
+		provider.InfoMock.On("Get", ctx, "first-vm").Return(info.Info{
+			Identifier: "first-vm",
+			Network:    []info.Network{{IPv4: []string{"10.0.0.1"}}},
+		}, nil).Once()
+		provider.InfoMock.On("Get", ctx, "second-vm").Return(info.Info{
+			Identifier: "second-vm",
+			Network:    []info.Network{{IPv4: []string{"10.0.0.2"}}},
+		}, nil).Once()
+
+		providerMetrics := metrics.NewProviderMetrics("anexia", "0.0.0-unit-tests")
+		manager := instanceManager{Provider: provider, vmCache: newVMCache(providerMetrics.VMCacheRequestsTotal)}
+
+		for _, ip := range []string{"10.0.0.1", "10.0.0.2"} {
+			_, err := manager.InstanceIDByNode(ctx, node(nil, ip))
+			require.NoError(t, err)
+		}
+
+		provider.InfoMock.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,304 @@
+// Package nodetags implements a controller syncing labels and taints of Nodes from tags of their VMs, letting
+// placement follow tags set in the Anexia Engine.
+package nodetags
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"go.anx.io/go-anxcloud/pkg/api"
+	corev1 "go.anx.io/go-anxcloud/pkg/apis/core/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// ManagedLabelsAnnotation lists the keys of the labels synced from tags, to remove them when the tag is removed
+	ManagedLabelsAnnotation = "anexia.com/tag-sync-labels"
+
+	// ManagedTaintsAnnotation lists the "$key:$effect" of the taints synced from tags, to remove them when the tag
+	// is removed
+	ManagedTaintsAnnotation = "anexia.com/tag-sync-taints"
+)
+
+// InstanceIDFunc returns the identifier of the VM of the given Node.
+type InstanceIDFunc func(ctx context.Context, node *v1.Node) (string, error)
+
+// Config configures which tags are synced to Nodes and how often.
+type Config struct {
+	// prefixes of tags synced as labels and taints, not synced when empty
+	LabelPrefix string
+	TaintPrefix string
+
+	Interval time.Duration
+}
+
+// Controller syncs labels and taints of Nodes from tags of their VMs.
+type Controller struct {
+	config     Config
+	logger     logr.Logger
+	k8s        kubernetes.Interface
+	api        api.API
+	instanceID InstanceIDFunc
+}
+
+// New creates a Controller syncing Nodes retrieved with k8sClient from the tags of their VMs retrieved with apiClient.
+func New(config Config, logger logr.Logger, k8sClient kubernetes.Interface, apiClient api.API, instanceID InstanceIDFunc) *Controller {
+	return &Controller{
+		config:     config,
+		logger:     logger,
+		k8s:        k8sClient,
+		api:        apiClient,
+		instanceID: instanceID,
+	}
+}
+
+// Run syncs all Nodes in the configured interval until ctx is done.
+func (c *Controller) Run(ctx context.Context) {
+	wait.UntilWithContext(ctx, c.syncAll, c.config.Interval)
+}
+
+func (c *Controller) syncAll(ctx context.Context) {
+	nodes, err := c.k8s.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		c.logger.Error(err, "Error listing Nodes, not syncing tags")
+		return
+	}
+
+	for _, node := range nodes.Items {
+		// Nodes without providerID are not initialized yet, matching them to VMs is left to the node controller
+		if node.Spec.ProviderID == "" {
+			continue
+		}
+
+		if err := c.syncNode(ctx, &node); err != nil {
+			c.logger.Error(err, "Error syncing tags to Node", "node", node.Name)
+		}
+	}
+}
+
+func (c *Controller) syncNode(ctx context.Context, node *v1.Node) error {
+	logger := c.logger.WithValues("node", node.Name)
+
+	identifier, err := c.instanceID(ctx, node)
+	if err != nil {
+		return fmt.Errorf("error retrieving VM identifier: %w", err)
+	}
+
+	res := corev1.Resource{Identifier: identifier}
+	if err := c.api.Get(ctx, &res); err != nil {
+		return fmt.Errorf("error retrieving tags of VM '%s': %w", identifier, err)
+	}
+
+	labels, taints, errs := c.config.parseTags(res.Tags)
+	for _, err := range errs {
+		logger.Error(err, "Ignoring invalid tag", "identifier", identifier)
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current, err := c.k8s.CoreV1().Nodes().Get(ctx, node.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		if !applyTags(current, labels, taints) {
+			return nil
+		}
+
+		logger.Info("Syncing labels and taints from VM tags", "labels", labels, "taints", taints)
+		_, err = c.k8s.CoreV1().Nodes().Update(ctx, current, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// parseTags returns the labels and taints represented by the given tags. Tags without one of the configured prefixes
+// are ignored, invalid ones are returned as errors.
+func (c Config) parseTags(tags []string) (map[string]string, []v1.Taint, []error) {
+	labels := make(map[string]string)
+	taints := make([]v1.Taint, 0)
+	errs := make([]error, 0)
+
+	for _, tag := range tags {
+		switch {
+		case c.LabelPrefix != "" && strings.HasPrefix(tag, c.LabelPrefix):
+			key, value, err := parseLabel(strings.TrimPrefix(tag, c.LabelPrefix))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid label tag '%s': %w", tag, err))
+				continue
+			}
+
+			labels[key] = value
+		case c.TaintPrefix != "" && strings.HasPrefix(tag, c.TaintPrefix):
+			taint, err := parseTaint(strings.TrimPrefix(tag, c.TaintPrefix))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid taint tag '%s': %w", tag, err))
+				continue
+			}
+
+			taints = append(taints, taint)
+		}
+	}
+
+	return labels, taints, errs
+}
+
+// parseLabel parses "$key=$value" or "$key", the latter resulting in an empty value.
+func parseLabel(s string) (string, string, error) {
+	key, value, _ := strings.Cut(s, "=")
+
+	if msgs := validation.IsQualifiedName(key); len(msgs) > 0 {
+		return "", "", fmt.Errorf("invalid key: %s", strings.Join(msgs, ", "))
+	}
+
+	if msgs := validation.IsValidLabelValue(value); len(msgs) > 0 {
+		return "", "", fmt.Errorf("invalid value: %s", strings.Join(msgs, ", "))
+	}
+
+	return key, value, nil
+}
+
+// parseTaint parses "$key=$value:$effect" or "$key:$effect".
+func parseTaint(s string) (v1.Taint, error) {
+	keyValue, effect, found := strings.Cut(s, ":")
+	if !found {
+		return v1.Taint{}, fmt.Errorf("missing effect")
+	}
+
+	key, value, err := parseLabel(keyValue)
+	if err != nil {
+		return v1.Taint{}, err
+	}
+
+	switch v1.TaintEffect(effect) {
+	case v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute:
+	default:
+		return v1.Taint{}, fmt.Errorf("unsupported effect '%s'", effect)
+	}
+
+	return v1.Taint{Key: key, Value: value, Effect: v1.TaintEffect(effect)}, nil
+}
+
+// applyTags sets the given labels and taints on the Node and removes labels and taints synced before but no longer
+// given, returning true if the Node was changed. Labels and taints set on the Node by other means are overridden
+// when tags with the same keys exist, but are never removed.
+func applyTags(node *v1.Node, labels map[string]string, taints []v1.Taint) bool {
+	changed := false
+
+	if node.Labels == nil {
+		node.Labels = make(map[string]string)
+	}
+
+	for _, key := range managedKeys(node, ManagedLabelsAnnotation) {
+		if _, ok := labels[key]; !ok {
+			delete(node.Labels, key)
+			changed = true
+		}
+	}
+
+	for key, value := range labels {
+		if current, ok := node.Labels[key]; !ok || current != value {
+			node.Labels[key] = value
+			changed = true
+		}
+	}
+
+	desiredTaints := make(map[string]v1.Taint, len(taints))
+	taintKeys := make([]string, 0, len(taints))
+	for _, taint := range taints {
+		if _, ok := desiredTaints[taintKey(taint)]; !ok {
+			taintKeys = append(taintKeys, taintKey(taint))
+		}
+
+		desiredTaints[taintKey(taint)] = taint
+	}
+
+	managedTaints := managedKeys(node, ManagedTaintsAnnotation)
+	nodeTaints := make([]v1.Taint, 0, len(node.Spec.Taints)+len(taints))
+
+	for _, taint := range node.Spec.Taints {
+		key := taintKey(taint)
+
+		if desired, ok := desiredTaints[key]; ok {
+			if desired.Value != taint.Value {
+				changed = true
+			}
+
+			nodeTaints = append(nodeTaints, desired)
+			delete(desiredTaints, key)
+		} else if slices.Contains(managedTaints, key) {
+			changed = true
+		} else {
+			nodeTaints = append(nodeTaints, taint)
+		}
+	}
+
+	// taints not yet on the Node, in the order of their tags
+	for _, key := range taintKeys {
+		if taint, ok := desiredTaints[key]; ok {
+			nodeTaints = append(nodeTaints, taint)
+			changed = true
+		}
+	}
+
+	node.Spec.Taints = nodeTaints
+
+	labelKeys := make([]string, 0, len(labels))
+	for key := range labels {
+		labelKeys = append(labelKeys, key)
+	}
+
+	if setManagedKeys(node, ManagedLabelsAnnotation, labelKeys) {
+		changed = true
+	}
+
+	if setManagedKeys(node, ManagedTaintsAnnotation, taintKeys) {
+		changed = true
+	}
+
+	return changed
+}
+
+func taintKey(taint v1.Taint) string {
+	return fmt.Sprintf("%s:%s", taint.Key, taint.Effect)
+}
+
+func managedKeys(node *v1.Node, annotation string) []string {
+	value := node.Annotations[annotation]
+	if value == "" {
+		return nil
+	}
+
+	return strings.Split(value, ",")
+}
+
+// setManagedKeys stores the given keys in the annotation, removing it when there are none. Returns true if the
+// annotation was changed.
+func setManagedKeys(node *v1.Node, annotation string, keys []string) bool {
+	sort.Strings(keys)
+	value := strings.Join(keys, ",")
+
+	if node.Annotations[annotation] == value {
+		return false
+	}
+
+	if value == "" {
+		delete(node.Annotations, annotation)
+		return true
+	}
+
+	if node.Annotations == nil {
+		node.Annotations = make(map[string]string)
+	}
+
+	node.Annotations[annotation] = value
+	return true
+}
@@ -0,0 +1,116 @@
+package nodetags
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNodeTags(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Node tag sync")
+}
+
+var _ = Describe("parseTags", func() {
+	config := Config{LabelPrefix: "k8s-label:", TaintPrefix: "k8s-taint:"}
+
+	It("parses labels and taints", func() {
+		labels, taints, errs := config.parseTags([]string{
+			"k8s-label:pool=ingress",
+			"k8s-label:example.com/flag",
+			"k8s-taint:dedicated=db:NoSchedule",
+			"k8s-taint:maintenance:NoExecute",
+			"unrelated",
+		})
+
+		Expect(errs).To(BeEmpty())
+		Expect(labels).To(Equal(map[string]string{"pool": "ingress", "example.com/flag": ""}))
+		Expect(taints).To(Equal([]v1.Taint{
+			{Key: "dedicated", Value: "db", Effect: v1.TaintEffectNoSchedule},
+			{Key: "maintenance", Effect: v1.TaintEffectNoExecute},
+		}))
+	})
+
+	It("returns errors for invalid tags", func() {
+		labels, taints, errs := config.parseTags([]string{
+			"k8s-label:not a key=value",
+			"k8s-taint:dedicated=db",
+			"k8s-taint:dedicated=db:Sometimes",
+		})
+
+		Expect(labels).To(BeEmpty())
+		Expect(taints).To(BeEmpty())
+		Expect(errs).To(HaveLen(3))
+	})
+
+	It("ignores tags when the prefix is not configured", func() {
+		labels, taints, errs := Config{TaintPrefix: "k8s-taint:"}.parseTags([]string{"k8s-label:pool=ingress"})
+
+		Expect(labels).To(BeEmpty())
+		Expect(taints).To(BeEmpty())
+		Expect(errs).To(BeEmpty())
+	})
+})
+
+var _ = Describe("applyTags", func() {
+	var node *v1.Node
+
+	BeforeEach(func() {
+		node = &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{"kubernetes.io/hostname": "node"},
+			},
+			Spec: v1.NodeSpec{
+				Taints: []v1.Taint{{Key: "other", Effect: v1.TaintEffectNoSchedule}},
+			},
+		}
+	})
+
+	It("adds labels and taints, recording them as managed", func() {
+		changed := applyTags(node, map[string]string{"pool": "ingress"}, []v1.Taint{
+			{Key: "dedicated", Value: "db", Effect: v1.TaintEffectNoSchedule},
+		})
+
+		Expect(changed).To(BeTrue())
+		Expect(node.Labels).To(HaveKeyWithValue("pool", "ingress"))
+		Expect(node.Labels).To(HaveKeyWithValue("kubernetes.io/hostname", "node"))
+		Expect(node.Spec.Taints).To(HaveLen(2))
+		Expect(node.Annotations).To(HaveKeyWithValue(ManagedLabelsAnnotation, "pool"))
+		Expect(node.Annotations).To(HaveKeyWithValue(ManagedTaintsAnnotation, "dedicated:NoSchedule"))
+	})
+
+	It("does not change a Node already in sync", func() {
+		labels := map[string]string{"pool": "ingress"}
+		taints := []v1.Taint{{Key: "dedicated", Value: "db", Effect: v1.TaintEffectNoSchedule}}
+
+		Expect(applyTags(node, labels, taints)).To(BeTrue())
+		Expect(applyTags(node, labels, taints)).To(BeFalse())
+	})
+
+	It("updates changed values", func() {
+		applyTags(node, map[string]string{"pool": "ingress"}, []v1.Taint{{Key: "dedicated", Value: "db", Effect: v1.TaintEffectNoSchedule}})
+
+		changed := applyTags(node, map[string]string{"pool": "workers"}, []v1.Taint{{Key: "dedicated", Value: "cache", Effect: v1.TaintEffectNoSchedule}})
+
+		Expect(changed).To(BeTrue())
+		Expect(node.Labels).To(HaveKeyWithValue("pool", "workers"))
+		Expect(node.Spec.Taints).To(ContainElement(v1.Taint{Key: "dedicated", Value: "cache", Effect: v1.TaintEffectNoSchedule}))
+		Expect(node.Spec.Taints).To(HaveLen(2))
+	})
+
+	It("removes managed labels and taints whose tags were removed, keeping others", func() {
+		applyTags(node, map[string]string{"pool": "ingress"}, []v1.Taint{{Key: "dedicated", Value: "db", Effect: v1.TaintEffectNoSchedule}})
+
+		changed := applyTags(node, map[string]string{}, []v1.Taint{})
+
+		Expect(changed).To(BeTrue())
+		Expect(node.Labels).NotTo(HaveKey("pool"))
+		Expect(node.Labels).To(HaveKey("kubernetes.io/hostname"))
+		Expect(node.Spec.Taints).To(Equal([]v1.Taint{{Key: "other", Effect: v1.TaintEffectNoSchedule}}))
+		Expect(node.Annotations).NotTo(HaveKey(ManagedLabelsAnnotation))
+		Expect(node.Annotations).NotTo(HaveKey(ManagedTaintsAnnotation))
+	})
+})
@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"go.anx.io/go-anxcloud/pkg/vsphere/powercontrol"
+)
+
+// power states reported by the Engine besides powercontrol.OnState and powercontrol.OffState
+const (
+	powerStateSuspended    powercontrol.State = "suspended"
+	powerStatePoweringOn   powercontrol.State = "poweringOn"
+	powerStatePoweringOff  powercontrol.State = "poweringOff"
+	powerStateShuttingDown powercontrol.State = "shuttingDown"
+	powerStateRebooting    powercontrol.State = "rebooting"
+	powerStateResetting    powercontrol.State = "resetting"
+)
+
+// vmStatusDeprovisioning is the status of VMs being deprovisioned, those count as shut down regardless of their
+// power state so their pods are evicted promptly.
+const vmStatusDeprovisioning = "deprovisioning"
+
+// powerStateShutdown maps every known power state to whether the VM counts as shut down. Suspended VMs and VMs
+// on their way to being powered off count as shut down, VMs on their way to being powered on or restarting do not.
+var powerStateShutdown = map[powercontrol.State]bool{
+	powercontrol.OnState:   false,
+	powerStatePoweringOn:   false,
+	powerStateRebooting:    false,
+	powerStateResetting:    false,
+	powercontrol.OffState:  true,
+	powerStatePoweringOff:  true,
+	powerStateShuttingDown: true,
+	powerStateSuspended:    true,
+}
+
+// UnknownPowerStateError is returned when the Engine reports a power state not known to the CCM.
+type UnknownPowerStateError struct {
+	State powercontrol.State
+}
+
+func (e UnknownPowerStateError) Error() string {
+	return fmt.Sprintf("unknown power state '%s'", e.State)
+}
+
+// isShutdown returns whether a VM with the given status and power state counts as shut down.
+func isShutdown(status string, state powercontrol.State) (bool, error) {
+	if strings.EqualFold(status, vmStatusDeprovisioning) {
+		return true, nil
+	}
+
+	shutdown, ok := powerStateShutdown[state]
+	if !ok {
+		return false, UnknownPowerStateError{State: state}
+	}
+
+	return shutdown, nil
+}
@@ -0,0 +1,235 @@
+// Package preflight implements read-only checks of a cloud-config against the Anexia Engine, reporting
+// misconfiguration before the cloud controller manager is deployed with it.
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/configuration"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/loadbalancer/address"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/loadbalancer/discovery"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/utils"
+
+	anexia "go.anx.io/go-anxcloud/pkg"
+	"go.anx.io/go-anxcloud/pkg/api"
+	"go.anx.io/go-anxcloud/pkg/api/types"
+	lbaasv1 "go.anx.io/go-anxcloud/pkg/apis/lbaas/v1"
+	"go.anx.io/go-anxcloud/pkg/client"
+)
+
+// Status is the outcome of a single check.
+type Status string
+
+const (
+	// StatusOK is reported for checks that passed
+	StatusOK Status = "OK"
+
+	// StatusWarning is reported for checks that passed, but found something the operator should look at
+	StatusWarning Status = "WARN"
+
+	// StatusFailed is reported for checks that failed
+	StatusFailed Status = "FAIL"
+)
+
+// Result is the outcome of a single check together with a human readable message.
+type Result struct {
+	Check   string
+	Status  Status
+	Message string
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("[%s] %s: %s", r.Status, r.Check, r.Message)
+}
+
+// Failed returns true if any of the given results has StatusFailed.
+func Failed(results []Result) bool {
+	for _, r := range results {
+		if r.Status == StatusFailed {
+			return true
+		}
+	}
+
+	return false
+}
+
+type checker struct {
+	config       configuration.ProviderConfig
+	api          api.API
+	legacyClient client.Client
+
+	results []Result
+}
+
+// Run executes all checks for the given config, using the given clients. The checks only read from the
+// Anexia Engine and never create, modify or delete anything.
+func Run(ctx context.Context, config configuration.ProviderConfig, apiClient api.API, legacyClient client.Client) []Result {
+	c := checker{
+		config:       config,
+		api:          apiClient,
+		legacyClient: legacyClient,
+	}
+
+	c.checkVirtualMachines(ctx)
+	c.checkLoadBalancerAPI(ctx)
+	c.checkLoadBalancers(ctx)
+	c.checkPrefixes(ctx)
+
+	return c.results
+}
+
+func (c *checker) ok(check, format string, args ...any) {
+	c.results = append(c.results, Result{Check: check, Status: StatusOK, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) warn(check, format string, args ...any) {
+	c.results = append(c.results, Result{Check: check, Status: StatusWarning, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) fail(check string, err error) {
+	c.results = append(c.results, Result{Check: check, Status: StatusFailed, Message: describeError(err)})
+}
+
+// checkVirtualMachines verifies the token by searching for the VMs of the cluster, the same way Nodes are matched
+// to VMs.
+func (c *checker) checkVirtualMachines(ctx context.Context) {
+	const check = "vsphere"
+
+	namePrefix := c.config.CustomerID
+	if namePrefix == "" {
+		namePrefix = "%"
+		c.warn(check, "customerID not configured, Nodes will be matched to VMs using a wildcard")
+	}
+
+	vms, err := anexia.NewAPI(c.legacyClient).VSphere().Search().ByName(ctx, fmt.Sprintf("%s-%%", namePrefix))
+	if err != nil {
+		c.fail(check, fmt.Errorf("error searching VMs: %w", err))
+		return
+	}
+
+	c.ok(check, "token is valid and can search VMs, found %d VMs named %q", len(vms), fmt.Sprintf("%s-%%", namePrefix))
+}
+
+// checkLoadBalancerAPI verifies the token has access to LBaaS, even when no LoadBalancer is configured.
+func (c *checker) checkLoadBalancerAPI(ctx context.Context) {
+	const check = "lbaas"
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var oc types.ObjectChannel
+	if err := c.api.List(ctx, &lbaasv1.LoadBalancer{}, api.ObjectChannel(&oc)); err != nil {
+		c.fail(check, fmt.Errorf("error listing LoadBalancers: %w", err))
+		return
+	}
+
+	count := 0
+	for range oc {
+		count++
+	}
+
+	c.ok(check, "token can list LoadBalancers, %d accessible", count)
+}
+
+func (c *checker) checkLoadBalancers(ctx context.Context) {
+	const check = "loadbalancer"
+
+	var identifiers []string
+
+	if c.config.AutoDiscoverLoadBalancer {
+		tag := fmt.Sprintf("%s-%s", c.config.AutoDiscoveryTagPrefix, c.config.ClusterName)
+		lbs, err := discovery.DiscoverLoadBalancers(ctx, c.api, tag)
+		if err != nil {
+			c.fail(check, err)
+			return
+		}
+
+		if len(lbs) == 0 {
+			c.fail(check, fmt.Errorf("auto discovery found no LoadBalancers tagged %q", tag))
+			return
+		}
+
+		c.ok(check, "auto discovery found %d LoadBalancers tagged %q", len(lbs), tag)
+		identifiers = lbs
+	} else if c.config.LoadBalancerIdentifier != "" {
+		identifiers = append([]string{c.config.LoadBalancerIdentifier}, c.config.SecondaryLoadBalancerIdentifiers...)
+	} else {
+		c.warn(check, "neither loadBalancerIdentifier nor autoDiscoverLoadBalancer configured, LoadBalancer support disabled")
+		return
+	}
+
+	for _, identifier := range identifiers {
+		lb := lbaasv1.LoadBalancer{Identifier: identifier}
+		if err := c.api.Get(ctx, &lb); err != nil {
+			c.fail(check, fmt.Errorf("error retrieving LoadBalancer %q: %w", identifier, err))
+			continue
+		}
+
+		if !lb.StateOK() {
+			c.fail(check, fmt.Errorf("LoadBalancer %q (%s) is in state %q", lb.Name, identifier, lb.State.Text))
+			continue
+		}
+
+		c.ok(check, "LoadBalancer %q (%s) exists and is healthy", lb.Name, identifier)
+	}
+}
+
+func (c *checker) checkPrefixes(ctx context.Context) {
+	const check = "prefix"
+
+	identifiers := c.config.LoadBalancerPrefixIdentifiers
+
+	// discovered prefixes are only used when no prefixes are configured, see the LoadBalancer manager
+	var autoDiscoveryName *string
+
+	if len(identifiers) == 0 {
+		if !c.config.AutoDiscoverLoadBalancer {
+			return
+		}
+
+		discovered, err := address.DiscoverPrefixes(ctx, c.api, c.config.ClusterName)
+		if err != nil {
+			c.fail(check, err)
+			return
+		}
+
+		if len(discovered) == 0 {
+			c.fail(check, fmt.Errorf("auto discovery found no prefixes tagged %q", address.PrefixDiscoveryTag(c.config.ClusterName)))
+			return
+		}
+
+		identifiers = discovered
+		autoDiscoveryName = &c.config.ClusterName
+	}
+
+	for _, identifier := range identifiers {
+		p, err := address.DescribePrefix(ctx, c.api, c.legacyClient, identifier, autoDiscoveryName)
+		if err != nil {
+			c.fail(check, fmt.Errorf("error retrieving prefix %q: %w", identifier, err))
+			continue
+		}
+
+		if autoDiscoveryName != nil && !p.AddressDiscovered {
+			c.warn(check, "prefix %s (%s) has no address tagged %q, falling back to calculated VIP %s",
+				p.Prefix.String(), identifier, "kubernetes-lb-vip-"+*autoDiscoveryName, p.Address,
+			)
+			continue
+		}
+
+		c.ok(check, "prefix %s (%s, %s) resolves, external IP %s", p.Prefix.String(), identifier, p.Family, p.Address)
+	}
+}
+
+// describeError adds a hint about the token to errors caused by it.
+func describeError(err error) string {
+	switch utils.HTTPStatusCode(err) {
+	case http.StatusUnauthorized:
+		return fmt.Sprintf("token is invalid or expired: %v", err)
+	case http.StatusForbidden:
+		return fmt.Sprintf("token lacks permission: %v", err)
+	default:
+		return err.Error()
+	}
+}
@@ -0,0 +1,73 @@
+package preflight
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/configuration"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.anx.io/go-anxcloud/pkg/api/mock"
+	gs "go.anx.io/go-anxcloud/pkg/apis/common/gs"
+	lbaasv1 "go.anx.io/go-anxcloud/pkg/apis/lbaas/v1"
+)
+
+func TestPreflight(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Preflight checks")
+}
+
+var _ = Describe("checkLoadBalancers", func() {
+	var apiClient mock.API
+	var c *checker
+
+	BeforeEach(func() {
+		apiClient = mock.NewMockAPI()
+
+		apiClient.FakeExisting(&lbaasv1.LoadBalancer{
+			Identifier: "healthy-lb",
+			Name:       "healthy",
+			HasState:   gs.HasState{State: gs.State{Type: gs.StateTypeOK}},
+		})
+
+		apiClient.FakeExisting(&lbaasv1.LoadBalancer{
+			Identifier: "broken-lb",
+			Name:       "broken",
+			HasState:   gs.HasState{State: lbaasv1.DeploymentError},
+		})
+
+		c = &checker{api: apiClient}
+	})
+
+	It("reports healthy LoadBalancers", func() {
+		c.config = configuration.ProviderConfig{LoadBalancerIdentifier: "healthy-lb"}
+		c.checkLoadBalancers(context.TODO())
+
+		Expect(c.results).To(HaveLen(1))
+		Expect(c.results[0].Status).To(Equal(StatusOK))
+		Expect(Failed(c.results)).To(BeFalse())
+	})
+
+	It("fails for unhealthy and missing secondary LoadBalancers", func() {
+		c.config = configuration.ProviderConfig{
+			LoadBalancerIdentifier:           "healthy-lb",
+			SecondaryLoadBalancerIdentifiers: []string{"broken-lb", "missing-lb"},
+		}
+		c.checkLoadBalancers(context.TODO())
+
+		Expect(c.results).To(HaveLen(3))
+		Expect(c.results[1].Status).To(Equal(StatusFailed))
+		Expect(c.results[1].Message).To(ContainSubstring("broken-lb"))
+		Expect(c.results[2].Status).To(Equal(StatusFailed))
+		Expect(c.results[2].Message).To(ContainSubstring("missing-lb"))
+		Expect(Failed(c.results)).To(BeTrue())
+	})
+
+	It("warns when LoadBalancer support is disabled", func() {
+		c.config = configuration.ProviderConfig{}
+		c.checkLoadBalancers(context.TODO())
+
+		Expect(c.results).To(HaveLen(1))
+		Expect(c.results[0].Status).To(Equal(StatusWarning))
+	})
+})
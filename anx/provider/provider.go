@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,11 +11,16 @@ import (
 
 	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/metrics"
 	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/component-base/metrics/legacyregistry"
 
 	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/configuration"
 	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/loadbalancer"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/loadbalancer/gateway"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/nodetags"
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/tracing"
 
 	anexia "go.anx.io/go-anxcloud/pkg"
 	"go.anx.io/go-anxcloud/pkg/api"
@@ -27,6 +33,13 @@ import (
 const (
 	featureNameLoadBalancer = "load_balancer_provisioning"
 	featureNameInstancesV2  = "instances_v2"
+	featureNameZones        = "zones"
+
+	// configReloadInterval is the interval in which the cloud-config file is checked for changes
+	configReloadInterval = 30 * time.Second
+
+	// tracingShutdownTimeout is the time given to export pending spans when stopping
+	tracingShutdownTimeout = 5 * time.Second
 )
 
 var Version = "v0.0.0-unreleased"
@@ -34,6 +47,7 @@ var Version = "v0.0.0-unreleased"
 type Provider interface {
 	anexia.API
 	Config() *configuration.ProviderConfig
+	GenericClient() api.API
 }
 
 type anxProvider struct {
@@ -45,11 +59,14 @@ type anxProvider struct {
 	genericClient api.API
 	legacyClient  client.Client
 
-	instanceManager     cloudprovider.InstancesV2
+	instanceManager     *instanceManager
 	loadBalancerManager cloudprovider.LoadBalancer
 
 	// providerMetrics is used to collect metrics inside this provider
 	providerMetrics metrics.ProviderMetrics
+
+	// shutdownTracing flushes pending spans and stops exporting them
+	shutdownTracing func(context.Context) error
 }
 
 func newAnxProvider(config configuration.ProviderConfig) (*anxProvider, error) {
@@ -61,9 +78,21 @@ func newAnxProvider(config configuration.ProviderConfig) (*anxProvider, error) {
 
 	logger := klog.NewKlogr()
 
-	httpClient := http.Client{Timeout: 30 * time.Second}
+	shutdownTracing, err := tracing.Setup(context.Background(), &config, Version)
+	if err != nil {
+		return nil, fmt.Errorf("could not set up tracing. %w", err)
+	}
 
 	providerMetrics := setupProviderMetrics()
+
+	httpClient := http.Client{
+		Timeout:   30 * time.Second,
+		Transport: providerMetrics.Transport(http.DefaultTransport),
+	}
+
+	if config.TracingEndpoint != "" {
+		httpClient.Transport = tracing.Transport(httpClient.Transport)
+	}
 	legacyClient, err := client.New(
 		client.TokenFromString(config.Token),
 		client.WithMetricReceiver(providerMetrics.MetricReceiver),
@@ -92,6 +121,7 @@ func newAnxProvider(config configuration.ProviderConfig) (*anxProvider, error) {
 		logger:          logger.WithName("anx/provider"),
 		config:          &config,
 		providerMetrics: providerMetrics,
+		shutdownTracing: shutdownTracing,
 	}, nil
 }
 
@@ -99,7 +129,24 @@ func (a *anxProvider) Initialize(builder cloudprovider.ControllerClientBuilder,
 	a.logger.Info("Anexia provider initializing", "version", Version)
 
 	a.initializeLoadBalancerManager(builder)
-	a.instanceManager = &instanceManager{Provider: a}
+	a.instanceManager = &instanceManager{
+		Provider:           a,
+		vmCache:            newVMCache(a.providerMetrics.VMCacheRequestsTotal),
+		unknownPowerStates: a.providerMetrics.VMUnknownPowerStatesTotal,
+	}
+
+	a.startNodeTagSync(builder, stop)
+	a.startGatewayController(builder, stop)
+	a.watchConfig(stop)
+	a.stopTracing(stop)
+
+	if discoverer, ok := a.loadBalancerManager.(loadbalancer.Discoverer); ok {
+		go discoverer.RunDiscovery(wait.ContextForChannel(stop))
+	}
+
+	if collector, ok := a.loadBalancerManager.(loadbalancer.StateCollector); ok {
+		go collector.RunStateCollection(wait.ContextForChannel(stop))
+	}
 
 	if a.config.CustomerID != "" {
 		klog.Infof("running with customer prefix '%s'", a.config.CustomerID)
@@ -122,6 +169,15 @@ func (a *anxProvider) initializeLoadBalancerManager(builder cloudprovider.Contro
 	config := a.Config()
 	logger := a.logger.WithName("LoadBalancer")
 
+	if !config.AutoDiscoverLoadBalancer && config.LoadBalancerIdentifier == "" {
+		logger.Info("Neither loadBalancerIdentifier nor autoDiscoverLoadBalancer configured, LoadBalancer support disabled")
+		return
+	}
+
+	if config.ServiceMetricLabels {
+		a.providerMetrics.SetServiceLabelLimit(config.ServiceMetricLabelsLimit)
+	}
+
 	if lb, err := loadbalancer.New(config, logger, k8sClient, a.genericClient, a.legacyClient, a.providerMetrics); err != nil {
 		a.logger.Error(err, "Error initializing LoadBalancer manager")
 	} else {
@@ -129,6 +185,146 @@ func (a *anxProvider) initializeLoadBalancerManager(builder cloudprovider.Contro
 	}
 }
 
+// stopTracing exports pending spans once the given channel is closed.
+func (a *anxProvider) stopTracing(stop <-chan struct{}) {
+	if a.shutdownTracing == nil {
+		return
+	}
+
+	go func() {
+		<-stop
+
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+
+		if err := a.shutdownTracing(ctx); err != nil {
+			a.logger.Error(err, "Error exporting pending spans")
+		}
+	}()
+}
+
+// startNodeTagSync starts syncing labels and taints of Nodes from the tags of their VMs, when enabled.
+func (a *anxProvider) startNodeTagSync(builder cloudprovider.ControllerClientBuilder, stop <-chan struct{}) {
+	config := a.Config()
+	logger := a.logger.WithName("NodeTagSync")
+
+	if config.NodeLabelTagPrefix == "" && config.NodeTaintTagPrefix == "" {
+		logger.V(1).Info("Neither nodeLabelTagPrefix nor nodeTaintTagPrefix configured, not syncing VM tags to Nodes")
+		return
+	}
+
+	if builder == nil {
+		logger.Info("No kubernetes client available, not syncing VM tags to Nodes")
+		return
+	}
+
+	k8sClient, err := builder.Client("node-tag-sync")
+	if err != nil {
+		logger.Error(err, "Error creating kubernetes client, not syncing VM tags to Nodes")
+		return
+	}
+
+	controller := nodetags.New(nodetags.Config{
+		LabelPrefix: config.NodeLabelTagPrefix,
+		TaintPrefix: config.NodeTaintTagPrefix,
+		Interval:    config.NodeTagSyncInterval,
+	}, logger, k8sClient, a.genericClient, a.instanceManager.InstanceIDByNode)
+
+	go controller.Run(wait.ContextForChannel(stop))
+}
+
+// startGatewayController starts reconciling Gateways of the handled GatewayClasses via LBaaS, when enabled.
+func (a *anxProvider) startGatewayController(builder cloudprovider.ControllerClientBuilder, stop <-chan struct{}) {
+	config := a.Config()
+	logger := a.logger.WithName("Gateway")
+
+	if config.GatewayControllerName == "" {
+		logger.V(1).Info("No gatewayControllerName configured, Gateway API support disabled")
+		return
+	}
+
+	if a.loadBalancerManager == nil {
+		logger.Info("LoadBalancer support disabled, not reconciling Gateways")
+		return
+	}
+
+	if builder == nil {
+		logger.Info("No kubernetes client available, not reconciling Gateways")
+		return
+	}
+
+	k8sClient, err := builder.Client("gateway")
+	if err != nil {
+		logger.Error(err, "Error creating kubernetes client, not reconciling Gateways")
+		return
+	}
+
+	restConfig, err := builder.Config("gateway")
+	if err != nil {
+		logger.Error(err, "Error creating kubernetes client config, not reconciling Gateways")
+		return
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		logger.Error(err, "Error creating dynamic kubernetes client, not reconciling Gateways")
+		return
+	}
+
+	controller := gateway.New(gateway.Config{
+		ControllerName: config.GatewayControllerName,
+		ClusterName:    config.ClusterName,
+		Interval:       config.GatewaySyncInterval,
+	}, logger, k8sClient, dynamicClient, a.loadBalancerManager)
+
+	go controller.Run(wait.ContextForChannel(stop))
+}
+
+// watchConfig starts watching the cloud-config file, applying changed LoadBalancer settings at runtime.
+func (a *anxProvider) watchConfig(stop <-chan struct{}) {
+	managerOptions, err := configuration.GetManagerOptions()
+	if err != nil {
+		a.logger.Error(err, "Error retrieving manager options, not watching cloud-config for changes")
+		return
+	}
+
+	path := managerOptions.KubeCloudShared.CloudProvider.CloudConfigFile
+	if path == "" {
+		a.logger.V(1).Info("No cloud-config file given, not watching it for changes")
+		return
+	}
+
+	reloader, _ := a.loadBalancerManager.(loadbalancer.Reloader)
+
+	logger := a.logger.WithValues("cloud-config", path)
+	ctx := logr.NewContext(wait.ContextForChannel(stop), logger)
+
+	go configuration.Watch(ctx, path, configReloadInterval, func(config configuration.ProviderConfig, err error) {
+		// the service controller is only started when LoadBalancer support was enabled on startup
+		if err == nil && reloader == nil {
+			if config.AutoDiscoverLoadBalancer || config.LoadBalancerIdentifier != "" {
+				logger.Info("cloud-config changed and enables LoadBalancer support, which requires restarting the CCM")
+				a.providerMetrics.ConfigReloadsTotal.WithLabelValues("rejected").Inc()
+			}
+
+			return
+		}
+
+		if err == nil {
+			logger.Info("cloud-config changed, reloading LoadBalancer configuration")
+			err = reloader.Reload(ctx, &config)
+		}
+
+		if err != nil {
+			logger.Error(err, "Rejected changed cloud-config, keeping the previous configuration")
+			a.providerMetrics.ConfigReloadsTotal.WithLabelValues("rejected").Inc()
+			return
+		}
+
+		a.providerMetrics.ConfigReloadsTotal.WithLabelValues("applied").Inc()
+	})
+}
+
 func (a anxProvider) LoadBalancer() (cloudprovider.LoadBalancer, bool) {
 	if a.loadBalancerManager == nil {
 		return nil, false
@@ -151,8 +347,18 @@ func (a anxProvider) InstancesV2() (cloudprovider.InstancesV2, bool) {
 	return a.instanceManager, true
 }
 
+// Zones is only enabled when the name of the local Node is given, see GetZone.
 func (a anxProvider) Zones() (cloudprovider.Zones, bool) {
-	return nil, false
+	if a.instanceManager == nil {
+		return nil, false
+	}
+
+	if _, err := localNodeName(); err != nil {
+		return nil, false
+	}
+
+	a.providerMetrics.MarkFeatureEnabled(featureNameZones)
+	return a.instanceManager, true
 }
 
 func (a anxProvider) Clusters() (cloudprovider.Clusters, bool) {
@@ -175,6 +381,10 @@ func (a anxProvider) Config() *configuration.ProviderConfig {
 	return a.config
 }
 
+func (a anxProvider) GenericClient() api.API {
+	return a.genericClient
+}
+
 var registerOnce sync.Once
 
 func setupProviderMetrics() metrics.ProviderMetrics {
@@ -192,10 +402,20 @@ func setupProviderMetrics() metrics.ProviderMetrics {
 		legacyregistry.MustRegister(providerMetrics.ReconciliationRetrievedResourcesTotal)
 		legacyregistry.MustRegister(providerMetrics.HttpClientRequestCount)
 		legacyregistry.MustRegister(providerMetrics.HttpClientRequestInFlight)
+		legacyregistry.MustRegister(providerMetrics.HttpClientRequestDuration)
+		legacyregistry.MustRegister(providerMetrics.HttpClientRateLimitedTotal)
+		legacyregistry.MustRegister(providerMetrics.HttpClientRateLimitRetryAfterTotal)
+		legacyregistry.MustRegister(providerMetrics.ConfigReloadsTotal)
+		legacyregistry.MustRegister(providerMetrics.LoadBalancerSecondaryInSync)
+		legacyregistry.MustRegister(providerMetrics.VMCacheRequestsTotal)
+		legacyregistry.MustRegister(providerMetrics.VMUnknownPowerStatesTotal)
+		legacyregistry.MustRegister(providerMetrics.LastSuccessfulReconcileTimestamp)
+		legacyregistry.MustRegister(providerMetrics.LoadBalancerResources)
 	})
 
 	providerMetrics.MarkFeatureDisabled(featureNameLoadBalancer)
 	providerMetrics.MarkFeatureDisabled(featureNameInstancesV2)
+	providerMetrics.MarkFeatureDisabled(featureNameZones)
 	return providerMetrics
 }
 
@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/configuration"
+)
+
+// formatProviderID returns the providerID for the VM with the given identifier, qualified with the given location
+// code unless that is empty: "anexia://$location/$identifier" or "anexia://$identifier".
+func formatProviderID(identifier, location string) string {
+	if location == "" {
+		return configuration.CloudProviderScheme + identifier
+	}
+
+	return fmt.Sprintf("%s%s/%s", configuration.CloudProviderScheme, location, identifier)
+}
+
+// parseProviderID returns the VM identifier and, if present, the location code of the given providerID. Both
+// formats written by formatProviderID are accepted, as well as plain VM identifiers.
+func parseProviderID(providerID string) (identifier, location string, err error) {
+	identifier = strings.TrimPrefix(providerID, configuration.CloudProviderScheme)
+
+	if before, after, found := strings.Cut(identifier, "/"); found {
+		location, identifier = before, after
+		if location == "" || strings.Contains(identifier, "/") {
+			return "", "", fmt.Errorf("malformed providerID '%s'", providerID)
+		}
+	}
+
+	if identifier == "" {
+		return "", "", fmt.Errorf("malformed providerID '%s'", providerID)
+	}
+
+	return identifier, location, nil
+}
+
+// providerID returns the providerID to write for the VM with the given identifier: "anexia://$location/$identifier"
+// when enabled in the config, otherwise the plain identifier as always reported before.
+func (i *instanceManager) providerID(identifier, location string) string {
+	if !i.Config().ProviderIDLocation {
+		return identifier
+	}
+
+	return formatProviderID(identifier, location)
+}
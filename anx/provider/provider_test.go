@@ -17,8 +17,9 @@ import (
 var _ = Describe("Initialization", func() {
 	It("should initialize a new provider", func() {
 		provider, err := newAnxProvider(configuration.ProviderConfig{
-			Token:      "RANDOME_VALUE",
-			CustomerID: "CUSTOMER",
+			Token:                  "RANDOME_VALUE",
+			CustomerID:             "CUSTOMER",
+			LoadBalancerIdentifier: "LOADBALANCER",
 		})
 		Expect(err).Error().ToNot(HaveOccurred())
 		Expect(provider).ToNot(BeNil())
@@ -68,6 +69,10 @@ var _ = Describe("Initialization", func() {
 		instancesV2, instancesV2Enabled := p.InstancesV2()
 		Expect(instancesV2Enabled).To(BeFalse())
 		Expect(instancesV2).To(BeNil())
+
+		zones, zonesEnabled := p.Zones()
+		Expect(zonesEnabled).To(BeFalse())
+		Expect(zones).To(BeNil())
 	})
 
 	Context("Register cloud provider", func() {
@@ -0,0 +1,45 @@
+package apimock
+
+import (
+	"context"
+
+	"go.anx.io/go-anxcloud/pkg/api/types"
+	corev1 "go.anx.io/go-anxcloud/pkg/apis/core/v1"
+)
+
+// ListResources returns an implementation of List for use with DoAndReturn, sending core/v1 Resources with the given
+// identifiers via the ObjectChannel option.
+func ListResources(identifiers ...string) func(context.Context, types.FilterObject, ...types.ListOption) error {
+	resources := make([]corev1.Resource, 0, len(identifiers))
+	for _, identifier := range identifiers {
+		resources = append(resources, corev1.Resource{Identifier: identifier})
+	}
+
+	return ListObjects(resources...)
+}
+
+// ListObjects returns an implementation of List for use with DoAndReturn, sending copies of the given objects via the
+// ObjectChannel option.
+func ListObjects[T any](objects ...T) func(context.Context, types.FilterObject, ...types.ListOption) error {
+	return func(_ context.Context, _ types.FilterObject, opts ...types.ListOption) error {
+		options := types.ListOptions{}
+		for _, opt := range opts {
+			if err := opt.ApplyToList(&options); err != nil {
+				return err
+			}
+		}
+
+		c := make(chan types.ObjectRetriever, len(objects))
+		for _, object := range objects {
+			c <- func(o types.Object) error {
+				*any(o).(*T) = object
+				return nil
+			}
+		}
+		close(c)
+
+		*options.ObjectChannel = c
+
+		return nil
+	}
+}
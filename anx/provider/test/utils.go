@@ -8,6 +8,8 @@ import (
 	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/configuration"
 	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/mocks"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.anx.io/go-anxcloud/pkg/api"
+	"go.anx.io/go-anxcloud/pkg/api/mock"
 	"go.anx.io/go-anxcloud/pkg/clouddns"
 	"go.anx.io/go-anxcloud/pkg/ipam"
 	"go.anx.io/go-anxcloud/pkg/lbaas"
@@ -44,6 +46,7 @@ type MockedProvider struct {
 	FrontendMock     *mocks.Frontend
 	ServerMock       *mocks.Server
 	BindMock         *mocks.Bind
+	GenericAPIMock   mock.API
 
 	ProviderConfig *configuration.ProviderConfig
 }
@@ -90,6 +93,7 @@ func GetMockedAnxProvider() MockedProvider {
 		FrontendMock:     lbFrontendMock,
 		ServerMock:       lbServerMock,
 		BindMock:         lbBindMock,
+		GenericAPIMock:   mock.NewMockAPI(),
 		ProviderConfig: &configuration.ProviderConfig{
 			Token:                  "<TOKEN>",
 			CustomerID:             "<CUSTOMER_ID>",
@@ -126,6 +130,10 @@ func (m MockedProvider) Config() *configuration.ProviderConfig {
 	return m.ProviderConfig
 }
 
+func (m MockedProvider) GenericClient() api.API {
+	return m.GenericAPIMock
+}
+
 func ProviderManagedNode(identifier string) v1.Node {
 	return v1.Node{
 		Spec: v1.NodeSpec{
@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/configuration"
+)
+
+func TestTracing(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Tracing")
+}
+
+var _ = Describe("Setup", func() {
+	It("keeps tracing disabled without endpoint", func() {
+		// other specs may have left a recording TracerProvider behind in the global delegate
+		previous := otel.GetTracerProvider()
+		DeferCleanup(func() { otel.SetTracerProvider(previous) })
+		otel.SetTracerProvider(noop.NewTracerProvider())
+
+		shutdown, err := Setup(context.TODO(), &configuration.ProviderConfig{}, "v0.0.0-unit-tests")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(shutdown(context.TODO())).To(Succeed())
+
+		_, span := Start(context.TODO(), "test")
+		Expect(span.IsRecording()).To(BeFalse())
+	})
+})
+
+var _ = Describe("spans", func() {
+	var recorder *tracetest.SpanRecorder
+
+	BeforeEach(func() {
+		previous := otel.GetTracerProvider()
+		DeferCleanup(func() { otel.SetTracerProvider(previous) })
+
+		recorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	})
+
+	It("records errors on ended spans", func() {
+		ctx, parent := Start(context.TODO(), "parent", attribute.String("k8s.service.name", "web"))
+		_, child := Start(ctx, "child")
+
+		End(child, errors.New("failed"))
+		End(parent, nil)
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(2))
+
+		Expect(spans[0].Name()).To(Equal("child"))
+		Expect(spans[0].Parent().SpanID()).To(Equal(spans[1].SpanContext().SpanID()))
+		Expect(spans[0].Status().Code).To(Equal(codes.Error))
+		Expect(spans[0].Events()).To(HaveLen(1))
+
+		Expect(spans[1].Status().Code).To(Equal(codes.Unset))
+		Expect(spans[1].Attributes()).To(ContainElement(attribute.String("k8s.service.name", "web")))
+	})
+
+	It("traces HTTP requests with their status code", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		DeferCleanup(server.Close)
+
+		ctx, parent := Start(context.TODO(), "parent")
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		client := http.Client{Transport: Transport(http.DefaultTransport)}
+		res, err := client.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Body.Close()).To(Succeed())
+
+		End(parent, nil)
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(2))
+		Expect(spans[0].Parent().SpanID()).To(Equal(spans[1].SpanContext().SpanID()))
+		Expect(spans[0].Attributes()).To(ContainElement(attribute.Int("http.response.status_code", http.StatusTooManyRequests)))
+	})
+})
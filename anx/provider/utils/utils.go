@@ -30,20 +30,28 @@ func IsUnauthorizedOrForbiddenError(err error) bool {
 		return false
 	}
 
+	statusCode := HTTPStatusCode(err)
+
+	return statusCode == http.StatusUnauthorized ||
+		statusCode == http.StatusForbidden
+}
+
+// HTTPStatusCode returns the HTTP status code of the response that caused the given error or 0 if the error
+// was not caused by an HTTP response.
+// NOTE: This helper only works for errors returned by go-anxcloud.
+func HTTPStatusCode(err error) int {
 	var (
 		genericAPIClientError api.HTTPError
 		legacyAPIClientError  *client.ResponseError
-		statusCode            int
 	)
 
 	if errors.As(err, &genericAPIClientError) {
-		statusCode = genericAPIClientError.StatusCode()
+		return genericAPIClientError.StatusCode()
 	} else if errors.As(err, &legacyAPIClientError) {
-		statusCode = legacyAPIClientError.Response.StatusCode
+		return legacyAPIClientError.Response.StatusCode
 	}
 
-	return statusCode == http.StatusUnauthorized ||
-		statusCode == http.StatusForbidden
+	return 0
 }
 
 // ErrUnauthorizedForbiddenBackoff is returned if an operation is blocked due to a recent unauthorized or forbidden request
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -24,3 +26,12 @@ var _ = DescribeTable("IsUnauthorizedOrForbiddenError", func(err error, expected
 	Entry("generic API unauthorized error", api.NewHTTPError(http.StatusUnauthorized, "FOO", nil, nil), BeTrue()),
 	Entry("generic API other error", api.NewHTTPError(http.StatusNotFound, "FOO", nil, nil), BeFalse()),
 )
+
+var _ = DescribeTable("HTTPStatusCode", func(err error, expected int) {
+	Expect(HTTPStatusCode(err)).To(Equal(expected))
+},
+	Entry("legacy API error", &client.ResponseError{Response: &http.Response{StatusCode: http.StatusForbidden}}, http.StatusForbidden),
+	Entry("generic API error", api.NewHTTPError(http.StatusNotFound, "FOO", nil, nil), http.StatusNotFound),
+	Entry("wrapped generic API error", fmt.Errorf("wrapped: %w", api.NewHTTPError(http.StatusUnauthorized, "FOO", nil, nil)), http.StatusUnauthorized),
+	Entry("other error", errors.New("foo"), 0),
+)
@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	corev1 "go.anx.io/go-anxcloud/pkg/apis/core/v1"
+	vminfo "go.anx.io/go-anxcloud/pkg/vsphere/info"
+	"go.anx.io/go-anxcloud/pkg/vsphere/powercontrol"
+	"k8s.io/apimachinery/pkg/util/cache"
+	k8smetrics "k8s.io/component-base/metrics"
+)
+
+const (
+	// vmCacheTTL is how long VM lookups are cached, short enough to notice changes to VMs in time while still
+	// saving most lookups of a single node sync
+	vmCacheTTL = 30 * time.Second
+
+	// vmCacheSize is the maximum number of cached lookups
+	vmCacheSize = 2048
+)
+
+// vmCacheKey identifies a cached lookup, kind being the type of lookup (also used as metric label)
+type vmCacheKey struct {
+	kind       string
+	identifier string
+}
+
+// vmCache caches VM info, power state and tag lookups by VM identifier, shared by all instanceManager methods.
+// Failed lookups are not cached.
+type vmCache struct {
+	lookups  *cache.LRUExpireCache
+	requests *k8smetrics.CounterVec
+}
+
+func newVMCache(requests *k8smetrics.CounterVec) *vmCache {
+	return &vmCache{
+		lookups:  cache.NewLRUExpireCache(vmCacheSize),
+		requests: requests,
+	}
+}
+
+// cachedLookup returns the cached result of the given kind of lookup for identifier or, if there is none, calls
+// lookup and caches its result. When c is nil, lookup is always called.
+func cachedLookup[T any](c *vmCache, kind, identifier string, lookup func() (T, error)) (T, error) {
+	if c == nil {
+		return lookup()
+	}
+
+	key := vmCacheKey{kind: kind, identifier: identifier}
+
+	if v, ok := c.lookups.Get(key); ok {
+		c.requests.WithLabelValues(kind, "hit").Inc()
+		return v.(T), nil
+	}
+
+	c.requests.WithLabelValues(kind, "miss").Inc()
+
+	v, err := lookup()
+	if err != nil {
+		return v, err
+	}
+
+	c.lookups.Add(key, v, vmCacheTTL)
+	return v, nil
+}
+
+// vmInfo returns the info of the VM with the given identifier, cached for vmCacheTTL.
+func (i *instanceManager) vmInfo(ctx context.Context, identifier string) (vminfo.Info, error) {
+	return cachedLookup(i.vmCache, "info", identifier, func() (vminfo.Info, error) {
+		return i.VSphere().Info().Get(ctx, identifier)
+	})
+}
+
+// vmTags returns the tags of the VM with the given identifier, cached for vmCacheTTL.
+func (i *instanceManager) vmTags(ctx context.Context, identifier string) ([]string, error) {
+	return cachedLookup(i.vmCache, "tags", identifier, func() ([]string, error) {
+		res := corev1.Resource{Identifier: identifier}
+		if err := i.GenericClient().Get(ctx, &res); err != nil {
+			return nil, err
+		}
+
+		return res.Tags, nil
+	})
+}
+
+// vmPowerState returns the power state of the VM with the given identifier, cached for vmCacheTTL.
+func (i *instanceManager) vmPowerState(ctx context.Context, identifier string) (powercontrol.State, error) {
+	return cachedLookup(i.vmCache, "power_state", identifier, func() (powercontrol.State, error) {
+		return i.VSphere().PowerControl().Get(ctx, identifier)
+	})
+}
@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/metrics"
+	tUtils "github.com/anexia-it/k8s-anexia-ccm/anx/provider/test"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.anx.io/go-anxcloud/pkg/vsphere/info"
+	"go.anx.io/go-anxcloud/pkg/vsphere/powercontrol"
+	kubemetrics "k8s.io/component-base/metrics"
+)
+
+func TestVMCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	newCachedManager := func() (*instanceManager, tUtils.MockedProvider, kubemetrics.KubeRegistry) {
+		providerMetrics := metrics.NewProviderMetrics("anexia", "0.0.0-unit-tests")
+		registry := kubemetrics.NewKubeRegistry()
+		registry.MustRegister(providerMetrics.VMCacheRequestsTotal)
+
+		provider := tUtils.GetMockedAnxProvider()
+		return &instanceManager{Provider: provider, vmCache: newVMCache(providerMetrics.VMCacheRequestsTotal)}, provider, registry
+	}
+
+	t.Run("CachesLookups", func(t *testing.T) {
+		t.Parallel()
+		manager, provider, registry := newCachedManager()
+		identifier := randomNodeIdentifier()
+
+		provider.InfoMock.On("Get", ctx, identifier).Return(info.Info{Name: "vm"}, nil).Once()
+		provider.PowerControlMock.On("Get", ctx, identifier).Return(powercontrol.OnState, nil).Once()
+
+		for range 2 {
+			vmInfo, err := manager.vmInfo(ctx, identifier)
+			require.NoError(t, err)
+			require.Equal(t, "vm", vmInfo.Name)
+
+			state, err := manager.vmPowerState(ctx, identifier)
+			require.NoError(t, err)
+			require.Equal(t, powercontrol.OnState, state)
+		}
+
+		provider.InfoMock.AssertExpectations(t)
+		provider.PowerControlMock.AssertExpectations(t)
+
+		require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+		# HELP cloud_provider_anexia_vm_cache_requests_total [ALPHA] Counter of VM info, power state and tag lookups grouped by cache and result (hit or miss)
+		# TYPE cloud_provider_anexia_vm_cache_requests_total counter
+		cloud_provider_anexia_vm_cache_requests_total{cache="info",result="hit"} 1
+		cloud_provider_anexia_vm_cache_requests_total{cache="info",result="miss"} 1
+		cloud_provider_anexia_vm_cache_requests_total{cache="power_state",result="hit"} 1
+		cloud_provider_anexia_vm_cache_requests_total{cache="power_state",result="miss"} 1
+		`), "cloud_provider_anexia_vm_cache_requests_total"))
+	})
+
+	t.Run("DoesNotCacheErrors", func(t *testing.T) {
+		t.Parallel()
+		manager, provider, _ := newCachedManager()
+		identifier := randomNodeIdentifier()
+
+		provider.InfoMock.On("Get", ctx, identifier).Return(info.Info{}, errors.New("engine unavailable")).Once()
+		provider.InfoMock.On("Get", ctx, identifier).Return(info.Info{Name: "vm"}, nil).Once()
+
+		_, err := manager.vmInfo(ctx, identifier)
+		require.Error(t, err)
+
+		vmInfo, err := manager.vmInfo(ctx, identifier)
+		require.NoError(t, err)
+		require.Equal(t, "vm", vmInfo.Name)
+
+		provider.InfoMock.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	vminfo "go.anx.io/go-anxcloud/pkg/vsphere/info"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	cloudprovider "k8s.io/cloud-provider"
+)
+
+// nodeNameEnvVar is the environment variable holding the name of the Node this process runs on, to be set via the
+// downward API. The hostname of a Pod is its own name, not the one of its Node.
+const nodeNameEnvVar = "NODE_NAME"
+
+var errNodeNameMissing = fmt.Errorf("name of the local Node not given via %s environment variable", nodeNameEnvVar)
+
+// instanceZone maps the location of a VM to its zone, the same way for Zones and InstanceMetadata.
+func instanceZone(info vminfo.Info) cloudprovider.Zone {
+	return cloudprovider.Zone{
+		FailureDomain: info.LocationCode,
+		Region:        info.LocationCountry,
+	}
+}
+
+// localNodeName returns the name of the Node this process runs on, as given via the downward API.
+func localNodeName() (types.NodeName, error) {
+	nodeName := os.Getenv(nodeNameEnvVar)
+	if nodeName == "" {
+		return "", errNodeNameMissing
+	}
+
+	return types.NodeName(nodeName), nil
+}
+
+// GetZone returns the zone of the VM this process runs on, found by the name of its Node given via the downward API.
+func (i *instanceManager) GetZone(ctx context.Context) (cloudprovider.Zone, error) {
+	nodeName, err := localNodeName()
+	if err != nil {
+		return cloudprovider.Zone{}, err
+	}
+
+	return i.GetZoneByNodeName(ctx, nodeName)
+}
+
+// GetZoneByProviderID returns the zone of the VM with the given providerID.
+func (i *instanceManager) GetZoneByProviderID(ctx context.Context, providerID string) (cloudprovider.Zone, error) {
+	identifier, _, err := parseProviderID(providerID)
+	if err != nil {
+		return cloudprovider.Zone{}, err
+	}
+
+	info, err := i.vmInfo(ctx, identifier)
+	if err != nil {
+		return cloudprovider.Zone{}, fmt.Errorf("could not get vm info: %w", err)
+	}
+
+	return instanceZone(info), nil
+}
+
+// GetZoneByNodeName returns the zone of the VM matching a Node with the given name. Only the name is known, so
+// node matching strategies relying on other Node fields find nothing.
+func (i *instanceManager) GetZoneByNodeName(ctx context.Context, nodeName types.NodeName) (cloudprovider.Zone, error) {
+	identifier, err := i.matchNode(ctx, &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: string(nodeName)}})
+	if err != nil {
+		return cloudprovider.Zone{}, err
+	}
+
+	return i.GetZoneByProviderID(ctx, identifier)
+}
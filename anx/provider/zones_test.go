@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/configuration"
+	tUtils "github.com/anexia-it/k8s-anexia-ccm/anx/provider/test"
+	"github.com/stretchr/testify/require"
+	"go.anx.io/go-anxcloud/pkg/vsphere/info"
+	"go.anx.io/go-anxcloud/pkg/vsphere/search"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestZones(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	const nodeName = "test-node"
+
+	vmInfo := info.Info{
+		LocationCountry: "AT",
+		LocationCode:    "AT04",
+	}
+
+	t.Run("GetZoneByProviderID", func(t *testing.T) {
+		t.Parallel()
+		provider := tUtils.GetMockedAnxProvider()
+		identifier := randomNodeIdentifier()
+		provider.InfoMock.On("Get", ctx, identifier).Return(vmInfo, nil)
+
+		manager := instanceManager{Provider: provider}
+
+		zone, err := manager.GetZoneByProviderID(ctx, fmt.Sprintf("%s%s", configuration.CloudProviderScheme, identifier))
+		require.NoError(t, err)
+		require.Equal(t, "AT04", zone.FailureDomain)
+		require.Equal(t, "AT", zone.Region)
+	})
+
+	t.Run("GetZoneByNodeName", func(t *testing.T) {
+		t.Parallel()
+		provider := tUtils.GetMockedAnxProvider()
+		identifier := randomNodeIdentifier()
+		provider.SearchMock.On("ByName", ctx, fmt.Sprintf("%s-%s", provider.Config().CustomerID, nodeName)).
+			Return([]search.VM{{Identifier: identifier}}, nil)
+		provider.InfoMock.On("Get", ctx, identifier).Return(vmInfo, nil)
+
+		manager := instanceManager{Provider: provider}
+
+		zone, err := manager.GetZoneByNodeName(ctx, types.NodeName(nodeName))
+		require.NoError(t, err)
+		require.Equal(t, "AT04", zone.FailureDomain)
+		require.Equal(t, "AT", zone.Region)
+	})
+
+	t.Run("GetZoneByNodeName/NotFound", func(t *testing.T) {
+		t.Parallel()
+		provider := tUtils.GetMockedAnxProvider()
+		provider.SearchMock.On("ByName", ctx, fmt.Sprintf("%s-%s", provider.Config().CustomerID, nodeName)).
+			Return([]search.VM{}, nil)
+		provider.SearchMock.On("ByName", ctx, nodeName).Return([]search.VM{}, nil)
+
+		manager := instanceManager{Provider: provider}
+
+		_, err := manager.GetZoneByNodeName(ctx, types.NodeName(nodeName))
+		require.ErrorIs(t, err, errNamedVirtualMachineNotFound)
+	})
+}
+
+func TestGetZone(t *testing.T) {
+	ctx := context.Background()
+	const nodeName = "test-node"
+
+	t.Run("NodeNameMissing", func(t *testing.T) {
+		t.Setenv(nodeNameEnvVar, "")
+
+		manager := instanceManager{Provider: tUtils.GetMockedAnxProvider()}
+
+		_, err := manager.GetZone(ctx)
+		require.ErrorIs(t, err, errNodeNameMissing)
+	})
+
+	t.Run("NodeNameGiven", func(t *testing.T) {
+		t.Setenv(nodeNameEnvVar, nodeName)
+
+		provider := tUtils.GetMockedAnxProvider()
+		identifier := randomNodeIdentifier()
+		provider.SearchMock.On("ByName", ctx, fmt.Sprintf("%s-%s", provider.Config().CustomerID, nodeName)).
+			Return([]search.VM{{Identifier: identifier}}, nil)
+		provider.InfoMock.On("Get", ctx, identifier).Return(info.Info{LocationCountry: "AT", LocationCode: "AT04"}, nil)
+
+		manager := instanceManager{Provider: provider}
+
+		zone, err := manager.GetZone(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "AT04", zone.FailureDomain)
+	})
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/configuration"
+	"github.com/spf13/cobra"
+)
+
+// addClusterNameFlag adds a --cluster-name flag to the given subcommand, which does not inherit the one of the root
+// command. Pass the value to readConfigFile.
+func addClusterNameFlag(cmd *cobra.Command, clusterName *string) {
+	cmd.Flags().StringVar(clusterName, "cluster-name", "", "name of the cluster, as given to the CCM with --cluster-name")
+}
+
+// readConfigFile parses the given cloud-config file the same way the provider does on startup, with the given cluster
+// name applied like the --cluster-name flag of the root command.
+func readConfigFile(path, clusterName string) (configuration.ProviderConfig, error) {
+	if clusterName != "" {
+		managerOptions, err := configuration.GetManagerOptions()
+		if err != nil {
+			return configuration.ProviderConfig{}, err
+		}
+
+		managerOptions.KubeCloudShared.ClusterName = clusterName
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return configuration.ProviderConfig{}, err
+	}
+	defer f.Close()
+
+	return configuration.NewProviderConfig(f)
+}
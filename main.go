@@ -53,6 +53,7 @@ func main() {
 		fss,
 		wait.NeverStop,
 	)
+	command.AddCommand(validateConfigCommand(), preflightCommand(), teardownLoadBalancersCommand(), webhookCommand())
 
 	logs.InitLogs()
 	defer logs.FlushLogs()
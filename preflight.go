@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/preflight"
+	"github.com/spf13/cobra"
+	"go.anx.io/go-anxcloud/pkg/api"
+	"go.anx.io/go-anxcloud/pkg/client"
+)
+
+var errPreflightFailed = errors.New("preflight checks failed")
+
+// preflightCommand returns a subcommand running read-only checks of the given cloud-config file against the
+// Anexia Engine, reporting misconfiguration before the CCM is deployed with it.
+func preflightCommand() *cobra.Command {
+	var clusterName string
+
+	cmd := &cobra.Command{
+		Use:          "preflight <cloud-config file>",
+		Short:        "Check the given cloud-config file against the Anexia Engine and exit",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := readConfigFile(args[0], clusterName)
+			if err != nil {
+				return err
+			}
+
+			legacyClient, err := client.New(client.TokenFromString(config.Token))
+			if err != nil {
+				return fmt.Errorf("could not create legacy anexia client. %w", err)
+			}
+
+			genericClient, err := api.NewAPI(api.WithClientOptions(client.TokenFromString(config.Token)))
+			if err != nil {
+				return fmt.Errorf("could not create generic anexia client. %w", err)
+			}
+
+			results := preflight.Run(cmd.Context(), config, genericClient, legacyClient)
+			for _, r := range results {
+				if _, err := fmt.Fprintln(cmd.OutOrStdout(), r); err != nil {
+					return err
+				}
+			}
+
+			if preflight.Failed(results) {
+				return errPreflightFailed
+			}
+
+			return nil
+		},
+	}
+
+	addClusterNameFlag(cmd, &clusterName)
+
+	return cmd
+}
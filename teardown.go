@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/loadbalancer/provisioning"
+	"github.com/spf13/cobra"
+	"go.anx.io/go-anxcloud/pkg/api"
+	"go.anx.io/go-anxcloud/pkg/client"
+)
+
+var (
+	errNoAutoDiscovery = errors.New("provisioned LoadBalancers are only known with autoDiscoverLoadBalancer set")
+	errNoClusterName   = errors.New("cluster name is required to find the provisioned LoadBalancers, set clusterName or pass --cluster-name")
+)
+
+// teardownLoadBalancersCommand returns a subcommand destroying the LBaaS LoadBalancers provisioned for the cluster of
+// the given cloud-config file, for decommissioning the cluster. Without --confirm, they are only listed.
+func teardownLoadBalancersCommand() *cobra.Command {
+	var (
+		confirm     bool
+		clusterName string
+	)
+
+	cmd := &cobra.Command{
+		Use:          "teardown-loadbalancers <cloud-config file>",
+		Short:        "Destroy the LBaaS LoadBalancers provisioned for the cluster and exit",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := readConfigFile(args[0], clusterName)
+			if err != nil {
+				return err
+			}
+
+			if !config.AutoDiscoverLoadBalancer {
+				return errNoAutoDiscovery
+			}
+
+			// an empty cluster name would match LoadBalancers tagged for no cluster in particular
+			if config.ClusterName == "" {
+				return errNoClusterName
+			}
+
+			genericClient, err := api.NewAPI(api.WithClientOptions(client.TokenFromString(config.Token)))
+			if err != nil {
+				return fmt.Errorf("could not create generic anexia client. %w", err)
+			}
+
+			tag := fmt.Sprintf("%s-%s", config.AutoDiscoveryTagPrefix, config.ClusterName)
+
+			if !confirm {
+				identifiers, err := provisioning.Provisioned(cmd.Context(), genericClient, tag)
+				if err != nil {
+					return err
+				}
+
+				for _, identifier := range identifiers {
+					if _, err := fmt.Fprintf(cmd.OutOrStdout(), "would destroy LoadBalancer %s\n", identifier); err != nil {
+						return err
+					}
+				}
+
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), "run again with --confirm to destroy them")
+				return err
+			}
+
+			identifiers, err := provisioning.Teardown(cmd.Context(), genericClient, tag)
+			for _, identifier := range identifiers {
+				if _, err := fmt.Fprintf(cmd.OutOrStdout(), "destroyed LoadBalancer %s\n", identifier); err != nil {
+					return err
+				}
+			}
+
+			return err
+		},
+	}
+
+	cmd.Flags().BoolVar(&confirm, "confirm", false, "destroy the provisioned LoadBalancers instead of only listing them")
+	addClusterNameFlag(cmd, &clusterName)
+
+	return cmd
+}
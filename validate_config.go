@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// validateConfigCommand returns a subcommand parsing and validating the given cloud-config file the same way the
+// provider does on startup, without connecting to the Engine or the Kubernetes cluster. Useful to check rendered
+// configuration in CI before rolling it out.
+func validateConfigCommand() *cobra.Command {
+	var clusterName string
+
+	cmd := &cobra.Command{
+		Use:          "validate-config <cloud-config file>",
+		Short:        "Validate the given cloud-config file and exit",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := readConfigFile(args[0], clusterName); err != nil {
+				return err
+			}
+
+			_, err := fmt.Fprintf(cmd.OutOrStdout(), "cloud-config %q is valid\n", args[0])
+			return err
+		},
+	}
+
+	addClusterNameFlag(cmd, &clusterName)
+
+	return cmd
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/anexia-it/k8s-anexia-ccm/anx/provider/loadbalancer/webhook"
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+)
+
+// webhookShutdownTimeout is the time in-flight requests are given to complete when the webhook is stopped.
+const webhookShutdownTimeout = 10 * time.Second
+
+// webhookCommand returns a subcommand serving the validating admission webhook for LoadBalancer Services via HTTPS.
+func webhookCommand() *cobra.Command {
+	var bindAddress, certFile, keyFile string
+
+	cmd := &cobra.Command{
+		Use:          "webhook",
+		Short:        "Serve a validating admission webhook rejecting invalid LoadBalancer Services",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			logger := logr.FromContextOrDiscard(ctx).WithName("webhook")
+
+			mux := http.NewServeMux()
+			mux.Handle("/validate-service", webhook.NewHandler(logger))
+			mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("ok"))
+			})
+
+			server := &http.Server{
+				Addr:              bindAddress,
+				Handler:           mux,
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			go func() {
+				<-ctx.Done()
+
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), webhookShutdownTimeout)
+				defer cancel()
+
+				if err := server.Shutdown(shutdownCtx); err != nil {
+					logger.Error(err, "Error shutting down webhook server")
+				}
+			}()
+
+			logger.Info("Serving validating admission webhook", "address", bindAddress)
+
+			if err := server.ListenAndServeTLS(certFile, keyFile); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&bindAddress, "bind-address", ":9443", "address to serve the webhook on")
+	cmd.Flags().StringVar(&certFile, "tls-cert-file", "", "file containing the TLS certificate to serve the webhook with")
+	cmd.Flags().StringVar(&keyFile, "tls-private-key-file", "", "file containing the private key of the TLS certificate")
+
+	_ = cmd.MarkFlagRequired("tls-cert-file")
+	_ = cmd.MarkFlagRequired("tls-private-key-file")
+
+	return cmd
+}